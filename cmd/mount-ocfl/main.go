@@ -10,62 +10,79 @@ import (
 	"path"
 	"syscall"
 
-	ocflfuse "github.com/srerickson/ocfl-fuse"
-
-	"github.com/hanwen/go-fuse/v2/fs"
-	"github.com/hanwen/go-fuse/v2/fuse"
+	ocflfuse "github.com/srerickson/ocfl-fuse/pkg/ocflfuse"
 )
 
 func main() {
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s [options] <storage-root> <object-id> <mountpoint>\n\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "Mount an OCFL object as a read-only filesystem.\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] <storage-root> <mountpoint>\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Mount an OCFL storage root, or a single object within it, as a read-only filesystem.\n\n")
 		fmt.Fprintf(os.Stderr, "Arguments:\n")
 		fmt.Fprintf(os.Stderr, "  storage-root   S3 URI (s3://bucket/prefix) or local path\n")
-		fmt.Fprintf(os.Stderr, "  object-id      OCFL object identifier\n")
 		fmt.Fprintf(os.Stderr, "  mountpoint     Local directory to mount the filesystem\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
 	}
 
-	versionFlag := flag.String("version", "", "Object version to mount (default: head/latest)")
+	objectID := flag.String("object", "", "Mount a single OCFL object (default: browse the whole storage root)")
+	versionFlag := flag.String("version", "", "Object version to mount, requires -object (default: head/latest)")
+	allVersions := flag.Bool("all-versions", false, "Expose every version under .versions, requires -object")
+	cacheDir := flag.String("cache-dir", "", "Enable a local read-through disk cache for S3 reads, stored in this directory")
+	cacheMaxBytes := flag.Int64("cache-max-bytes", 0, "Maximum on-disk size of -cache-dir; 0 means unbounded")
+	chunkSize := flag.Int64("chunk-size", 0, "Chunk size for S3 reads and cache population; 0 uses the default (4 MiB)")
+	entryTimeout := flag.Duration("entry-timeout", 0, "How long the kernel caches directory entries; 0 uses the default (1h)")
+	attrTimeout := flag.Duration("attr-timeout", 0, "How long the kernel caches file attributes; 0 uses the default (1h)")
+	negativeTimeout := flag.Duration("negative-timeout", 0, "How long the kernel caches negative lookups; 0 uses the default (1h)")
 	debug := flag.Bool("debug", false, "Enable FUSE debug output")
 	flag.Parse()
 
-	if flag.NArg() != 3 {
+	if flag.NArg() != 2 {
 		flag.Usage()
 		os.Exit(1)
 	}
 
 	storageRoot := flag.Arg(0)
-	objectID := flag.Arg(1)
-	mountpoint := flag.Arg(2)
+	mountpoint := flag.Arg(1)
 
 	ctx := context.Background()
 
-	result, err := ocflfuse.NewRoot(ctx, storageRoot, objectID, *versionFlag)
+	fuseOpts := ocflfuse.Options{
+		AllVersions:   *allVersions,
+		CacheDir:      *cacheDir,
+		CacheMaxBytes: *cacheMaxBytes,
+		ChunkSize:     *chunkSize,
+	}
+	result, err := ocflfuse.NewRoot(ctx, storageRoot, *objectID, *versionFlag, fuseOpts)
 	if err != nil {
 		log.Fatalf("%v", err)
 	}
 
 	log.Printf("Opened OCFL root (spec %s, layout %s)", result.Info.RootSpec, result.Info.Layout)
-	log.Printf("OCFL object %q version %s: %d files", result.Info.ObjectID, result.Info.Version, result.Info.FileCount)
+	if *objectID != "" {
+		log.Printf("OCFL object %q version %s: %d files", result.Info.ObjectID, result.Info.Version, result.Info.FileCount)
+		if *allVersions {
+			log.Printf("All versions: %v", result.Info.Versions)
+		}
+	}
 
 	// Create mountpoint if it doesn't exist
 	if err := os.MkdirAll(mountpoint, 0755); err != nil {
 		log.Fatalf("Failed to create mountpoint: %v", err)
 	}
 
-	opts := &fs.Options{
-		MountOptions: fuse.MountOptions{
-			FsName:  "ocfl-" + path.Base(objectID),
-			Name:    "ocfl",
-			Debug:   *debug,
-			Options: []string{"ro"},
-		},
+	fsName := "ocfl-" + path.Base(storageRoot)
+	if *objectID != "" {
+		fsName = "ocfl-" + path.Base(*objectID)
+	}
+	mountOpts := ocflfuse.MountOptions{
+		FsName:          fsName,
+		Debug:           *debug,
+		EntryTimeout:    *entryTimeout,
+		AttrTimeout:     *attrTimeout,
+		NegativeTimeout: *negativeTimeout,
 	}
 
-	server, err := fs.Mount(mountpoint, result.Root, opts)
+	server, err := ocflfuse.Mount(mountpoint, result, mountOpts)
 	if err != nil {
 		log.Fatalf("Failed to mount: %v", err)
 	}
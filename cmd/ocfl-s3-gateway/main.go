@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	ocflfuse "github.com/srerickson/ocfl-fuse/pkg/ocflfuse"
+	"github.com/srerickson/ocfl-fuse/pkg/ocfls3gw"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] <storage-root>\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Serve an OCFL storage root over a minimal S3-compatible HTTP API.\n\n")
+		fmt.Fprintf(os.Stderr, "Arguments:\n")
+		fmt.Fprintf(os.Stderr, "  storage-root   S3 URI (s3://bucket/prefix) or local path\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flag.PrintDefaults()
+	}
+
+	addr := flag.String("listen", ":9090", "Address to listen on")
+	cacheDir := flag.String("cache-dir", "", "Enable a local read-through disk cache for S3 reads, stored in this directory")
+	cacheMaxBytes := flag.Int64("cache-max-bytes", 0, "Maximum on-disk size of -cache-dir; 0 means unbounded")
+	chunkSize := flag.Int64("chunk-size", 0, "Chunk size for S3 reads and cache population; 0 uses the default (4 MiB)")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	storageRoot := flag.Arg(0)
+
+	gw, err := ocfls3gw.Open(context.Background(), storageRoot, ocflfuse.Options{
+		CacheDir:      *cacheDir,
+		CacheMaxBytes: *cacheMaxBytes,
+		ChunkSize:     *chunkSize,
+	})
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	log.Printf("Serving %s at %s", storageRoot, *addr)
+	log.Fatal(http.ListenAndServe(*addr, gw))
+}
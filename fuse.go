@@ -1,11 +1,13 @@
 package main
 
 import (
+	"container/list"
 	"context"
 	"fmt"
 	"io"
 	"log"
 	"strings"
+	"sync"
 	"syscall"
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
@@ -13,20 +15,37 @@ import (
 	"github.com/hanwen/go-fuse/v2/fuse"
 )
 
-// OCFLRoot is the FUSE root node. It holds the S3 client and the
+// OCFLRoot is the FUSE root node. It holds the S3 backend and the
 // logical-path-to-S3-key mapping derived from the OCFL inventory.
 type OCFLRoot struct {
 	fs.Inode
-	s3Client *s3.Client
-	bucket   string
-	files    map[string]string // logicalPath -> S3 key
+	backend *S3Backend
+	files   map[string]string // logicalPath -> S3 key
 }
 
 var _ = (fs.NodeOnAdder)((*OCFLRoot)(nil))
 
 func (r *OCFLRoot) OnAdd(ctx context.Context) {
-	for logicalPath, s3Key := range r.files {
-		dir := &r.Inode
+	populateFileTree(ctx, &r.Inode, r.backend, r.files)
+}
+
+// toS3Files converts a map of logicalPath -> content path (relative to the
+// storage root) into logicalPath -> full S3 key, by applying backend's
+// configured prefix.
+func toS3Files(backend *S3Backend, files map[string]string) map[string]string {
+	s3Files := make(map[string]string, len(files))
+	for logicalPath, contentPath := range files {
+		s3Files[logicalPath] = backend.fullKey(contentPath)
+	}
+	return s3Files
+}
+
+// populateFileTree adds a directory and file inode under parent for each
+// logicalPath -> S3 key pair in files, creating intermediate directories as
+// needed.
+func populateFileTree(ctx context.Context, parent *fs.Inode, backend *S3Backend, files map[string]string) {
+	for logicalPath, s3Key := range files {
+		dir := parent
 		parts := strings.Split(logicalPath, "/")
 
 		// Create parent directories
@@ -43,9 +62,8 @@ func (r *OCFLRoot) OnAdd(ctx context.Context) {
 		// Add file node
 		filename := parts[len(parts)-1]
 		fileNode := &OCFLFile{
-			s3Client: r.s3Client,
-			bucket:   r.bucket,
-			s3Key:    s3Key,
+			backend: backend,
+			s3Key:   s3Key,
 		}
 		child := dir.NewPersistentInode(ctx, fileNode, fs.StableAttr{Mode: syscall.S_IFREG})
 		dir.AddChild(filename, child, false)
@@ -64,14 +82,17 @@ func (d *OCFLDir) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse.AttrO
 	return 0
 }
 
-// OCFLFile is a file node backed by an S3 object.
+// OCFLFile is a file node backed by an S3 object. Reads are served through
+// backend's block cache when one is configured; otherwise each read issues
+// its own ranged GetObject.
 type OCFLFile struct {
 	fs.Inode
-	s3Client *s3.Client
-	bucket   string
-	s3Key    string
-	size     int64
-	sizeOK   bool
+	backend *S3Backend
+	s3Key   string
+
+	size   int64
+	etag   string
+	sizeOK bool
 }
 
 var _ = (fs.NodeGetattrer)((*OCFLFile)(nil))
@@ -80,8 +101,8 @@ var _ = (fs.NodeReader)((*OCFLFile)(nil))
 
 func (f *OCFLFile) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
 	if !f.sizeOK {
-		resp, err := f.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
-			Bucket: &f.bucket,
+		resp, err := f.backend.client.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket: &f.backend.bucket,
 			Key:    &f.s3Key,
 		})
 		if err != nil {
@@ -91,6 +112,9 @@ func (f *OCFLFile) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse.Attr
 		if resp.ContentLength != nil {
 			f.size = *resp.ContentLength
 		}
+		if resp.ETag != nil {
+			f.etag = *resp.ETag
+		}
 		f.sizeOK = true
 	}
 	out.Mode = 0644 | syscall.S_IFREG
@@ -103,9 +127,45 @@ func (f *OCFLFile) Open(ctx context.Context, flags uint32) (fs.FileHandle, uint3
 }
 
 func (f *OCFLFile) Read(ctx context.Context, fh fs.FileHandle, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
+	if f.backend.blockCache == nil || f.backend.blockSize <= 0 {
+		return f.readRange(ctx, dest, off)
+	}
+
+	blockSize := f.backend.blockSize
+	n := 0
+	for n < len(dest) {
+		block := (off + int64(n)) / blockSize
+		data, ok := f.backend.blockCache.get(f.s3Key, block)
+		if !ok {
+			var err error
+			data, err = f.fetchBlocks(ctx, block)
+			if err != nil {
+				if n > 0 {
+					break // serve what we already have
+				}
+				return nil, syscall.EIO
+			}
+		}
+		blockStart := block * blockSize
+		relOff := off + int64(n) - blockStart
+		if relOff < 0 || relOff > int64(len(data)) {
+			break
+		}
+		copied := copy(dest[n:], data[relOff:])
+		n += copied
+		if copied == 0 {
+			break // reached EOF within this block
+		}
+	}
+	return fuse.ReadResultData(dest[:n]), 0
+}
+
+// readRange serves a single read with its own ranged GetObject, used when no
+// block cache is configured.
+func (f *OCFLFile) readRange(ctx context.Context, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
 	rangeHeader := fmt.Sprintf("bytes=%d-%d", off, off+int64(len(dest))-1)
-	resp, err := f.s3Client.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: &f.bucket,
+	resp, err := f.backend.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &f.backend.bucket,
 		Key:    &f.s3Key,
 		Range:  &rangeHeader,
 	})
@@ -122,3 +182,117 @@ func (f *OCFLFile) Read(ctx context.Context, fh fs.FileHandle, dest []byte, off
 	}
 	return fuse.ReadResultData(data), 0
 }
+
+// fetchBlocks fetches startBlock plus backend.readAheadBlocks further blocks
+// in a single ranged GetObject, caching each block individually, and returns
+// startBlock's data.
+func (f *OCFLFile) fetchBlocks(ctx context.Context, startBlock int64) ([]byte, error) {
+	blockSize := f.backend.blockSize
+	start := startBlock * blockSize
+	end := start + blockSize*(1+f.backend.readAheadBlocks) - 1
+	rangeHeader := fmt.Sprintf("bytes=%d-%d", start, end)
+
+	resp, err := f.backend.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &f.backend.bucket,
+		Key:    &f.s3Key,
+		Range:  &rangeHeader,
+	})
+	if err != nil {
+		log.Printf("GetObject range error for %s: %v", f.s3Key, err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Printf("Read error for %s: %v", f.s3Key, err)
+		return nil, err
+	}
+
+	var first []byte
+	for i := int64(0); i*blockSize < int64(len(data)); i++ {
+		blockStart := i * blockSize
+		blockEnd := blockStart + blockSize
+		if blockEnd > int64(len(data)) {
+			blockEnd = int64(len(data))
+		}
+		block := data[blockStart:blockEnd]
+		f.backend.blockCache.put(f.s3Key, startBlock+i, block)
+		if i == 0 {
+			first = block
+		}
+	}
+	return first, nil
+}
+
+// blockCache is a bounded, in-memory LRU cache of fixed-size byte blocks,
+// keyed by (S3 key, block index). It lets OCFLFile.Read serve repeated and
+// sequential access to the same content without re-fetching the same bytes
+// from S3 on every FUSE read.
+type blockCache struct {
+	blockSize int64
+	maxBytes  int64
+
+	mu      sync.Mutex
+	lru     *list.List // of *blockCacheEntry, most recently used at the front
+	entries map[blockKey]*list.Element
+	size    int64
+}
+
+type blockKey struct {
+	s3Key string
+	block int64
+}
+
+type blockCacheEntry struct {
+	key  blockKey
+	data []byte
+}
+
+func newBlockCache(blockSize, maxBytes int64) *blockCache {
+	return &blockCache{
+		blockSize: blockSize,
+		maxBytes:  maxBytes,
+		lru:       list.New(),
+		entries:   make(map[blockKey]*list.Element),
+	}
+}
+
+func (c *blockCache) get(s3Key string, block int64) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[blockKey{s3Key, block}]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*blockCacheEntry).data, true
+}
+
+func (c *blockCache) put(s3Key string, block int64, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := blockKey{s3Key, block}
+	if elem, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(elem)
+		entry := elem.Value.(*blockCacheEntry)
+		c.size += int64(len(data)) - int64(len(entry.data))
+		entry.data = data
+	} else {
+		elem := c.lru.PushFront(&blockCacheEntry{key: key, data: data})
+		c.entries[key] = elem
+		c.size += int64(len(data))
+	}
+
+	for c.maxBytes > 0 && c.size > c.maxBytes {
+		back := c.lru.Back()
+		if back == nil {
+			return
+		}
+		c.lru.Remove(back)
+		entry := back.Value.(*blockCacheEntry)
+		delete(c.entries, entry.key)
+		c.size -= int64(len(entry.data))
+	}
+}
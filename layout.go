@@ -0,0 +1,267 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// LayoutExtension computes an OCFL object's storage path from its ID, per
+// one of the community storage-root layout extensions
+// (https://ocfl.github.io/extensions/). Configure is called once, with the
+// bytes of the layout's extensions/<name>/config.json (nil if the storage
+// root has none), before ObjectPath is used.
+type LayoutExtension interface {
+	Name() string
+	Configure(config json.RawMessage) error
+	ObjectPath(objectID string) string
+}
+
+// layoutRegistry maps an extension name (e.g.
+// "0003-hash-and-id-n-tuple-storage-layout") to a constructor for it.
+var layoutRegistry = map[string]func() LayoutExtension{}
+
+// RegisterLayout registers a LayoutExtension constructor under name, so
+// loadLayoutConfig can build one when a storage root's ocfl_layout.json
+// names it. Call this from an init function to support a private,
+// non-community layout without forking this package.
+func RegisterLayout(name string, newLayout func() LayoutExtension) {
+	layoutRegistry[name] = newLayout
+}
+
+func init() {
+	RegisterLayout("0002-flat-direct-storage-layout", func() LayoutExtension { return &flatDirectLayout{} })
+	RegisterLayout("0003-hash-and-id-n-tuple-storage-layout", func() LayoutExtension { return &hashNTupleLayout{} })
+	RegisterLayout("0004-hashed-n-tuple-storage-layout", func() LayoutExtension { return &hashedNTupleLayout{} })
+	RegisterLayout("0006-flat-omit-prefix-storage-layout", func() LayoutExtension { return &flatOmitPrefixLayout{} })
+	RegisterLayout("0007-n-tuple-omit-prefix-storage-layout", func() LayoutExtension { return &nTupleOmitPrefixLayout{} })
+}
+
+// --- 0002-flat-direct-storage-layout ---
+
+// flatDirectLayout uses the object ID directly as its storage path. It
+// takes no configuration.
+type flatDirectLayout struct{}
+
+func (flatDirectLayout) Name() string                     { return "0002-flat-direct-storage-layout" }
+func (flatDirectLayout) Configure(json.RawMessage) error   { return nil }
+func (flatDirectLayout) ObjectPath(objectID string) string { return objectID }
+
+// --- 0003-hash-and-id-n-tuple-storage-layout ---
+
+type hashAndIDNTupleConfig struct {
+	DigestAlgorithm string `json:"digestAlgorithm"`
+	TupleSize       int    `json:"tupleSize"`
+	NumberOfTuples  int    `json:"numberOfTuples"`
+}
+
+// hashNTupleLayout builds a path from N-tuples of the object ID's digest,
+// followed by a final directory named with the object ID itself.
+type hashNTupleLayout struct {
+	config hashAndIDNTupleConfig
+}
+
+func (hashNTupleLayout) Name() string { return "0003-hash-and-id-n-tuple-storage-layout" }
+
+func (l *hashNTupleLayout) Configure(config json.RawMessage) error {
+	return json.Unmarshal(config, &l.config)
+}
+
+func (l *hashNTupleLayout) ObjectPath(objectID string) string {
+	hashHex, ok := digestHex(l.config.DigestAlgorithm, objectID)
+	if !ok {
+		return objectID
+	}
+	parts := hashTuples(hashHex, l.config.TupleSize, l.config.NumberOfTuples)
+	parts = append(parts, objectID)
+	return strings.Join(parts, "/")
+}
+
+// --- 0004-hashed-n-tuple-storage-layout ---
+
+type hashedNTupleConfig struct {
+	DigestAlgorithm string `json:"digestAlgorithm"`
+	TupleSize       int    `json:"tupleSize"`
+	NumberOfTuples  int    `json:"numberOfTuples"`
+}
+
+// hashedNTupleLayout builds a path from N-tuples of the object ID's digest,
+// followed by a final directory named with the whole digest. Unlike
+// hashNTupleLayout, the object ID itself never appears in the path.
+type hashedNTupleLayout struct {
+	config hashedNTupleConfig
+}
+
+func (hashedNTupleLayout) Name() string { return "0004-hashed-n-tuple-storage-layout" }
+
+func (l *hashedNTupleLayout) Configure(config json.RawMessage) error {
+	return json.Unmarshal(config, &l.config)
+}
+
+func (l *hashedNTupleLayout) ObjectPath(objectID string) string {
+	hashHex, ok := digestHex(l.config.DigestAlgorithm, objectID)
+	if !ok {
+		return objectID
+	}
+	parts := hashTuples(hashHex, l.config.TupleSize, l.config.NumberOfTuples)
+	parts = append(parts, hashHex)
+	return strings.Join(parts, "/")
+}
+
+// --- 0006-flat-omit-prefix-storage-layout ---
+
+type flatOmitPrefixConfig struct {
+	Delimiter string `json:"delimiter"`
+}
+
+// flatOmitPrefixLayout strips everything up to and including the last
+// occurrence of Delimiter from the object ID, using what remains directly
+// as a flat storage path.
+type flatOmitPrefixLayout struct {
+	config flatOmitPrefixConfig
+}
+
+func (flatOmitPrefixLayout) Name() string { return "0006-flat-omit-prefix-storage-layout" }
+
+func (l *flatOmitPrefixLayout) Configure(config json.RawMessage) error {
+	return json.Unmarshal(config, &l.config)
+}
+
+func (l *flatOmitPrefixLayout) ObjectPath(objectID string) string {
+	return omitPrefix(objectID, l.config.Delimiter)
+}
+
+// --- 0007-n-tuple-omit-prefix-storage-layout ---
+
+type nTupleOmitPrefixConfig struct {
+	Delimiter         string `json:"delimiter"`
+	TupleSize         int    `json:"tupleSize"`
+	NumberOfTuples    int    `json:"numberOfTuples"`
+	ZeroPadding       string `json:"zeroPadding"`
+	ReverseObjectRoot bool   `json:"reverseObjectRoot"`
+}
+
+// nTupleOmitPrefixLayout strips everything up to and including the last
+// occurrence of Delimiter from the object ID, zero-pads what remains (on the
+// side named by ZeroPadding) up to TupleSize*NumberOfTuples characters,
+// optionally reverses it, then builds a path from NumberOfTuples tuples of
+// TupleSize characters taken left to right, followed by a final directory
+// named with the whole (unpadded) remainder.
+type nTupleOmitPrefixLayout struct {
+	config nTupleOmitPrefixConfig
+}
+
+func (nTupleOmitPrefixLayout) Name() string { return "0007-n-tuple-omit-prefix-storage-layout" }
+
+func (l *nTupleOmitPrefixLayout) Configure(config json.RawMessage) error {
+	if err := json.Unmarshal(config, &l.config); err != nil {
+		return err
+	}
+	if l.config.ZeroPadding == "" {
+		l.config.ZeroPadding = "left"
+	}
+	return nil
+}
+
+func (l *nTupleOmitPrefixLayout) ObjectPath(objectID string) string {
+	remainder := omitPrefix(objectID, l.config.Delimiter)
+	padded := padTuples(remainder, l.config.TupleSize, l.config.NumberOfTuples, l.config.ZeroPadding)
+	if l.config.ReverseObjectRoot {
+		padded = reverseString(padded)
+	}
+	parts := leftTuples(padded, l.config.TupleSize, l.config.NumberOfTuples)
+	parts = append(parts, remainder)
+	return strings.Join(parts, "/")
+}
+
+// --- shared helpers ---
+
+func digestHex(algorithm, s string) (string, bool) {
+	switch algorithm {
+	case "sha256":
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:]), true
+	case "sha512":
+		sum := sha512.Sum512([]byte(s))
+		return hex.EncodeToString(sum[:]), true
+	default:
+		return "", false
+	}
+}
+
+// hashTuples splits the start of hashHex into numberOfTuples substrings of
+// tupleSize characters each.
+func hashTuples(hashHex string, tupleSize, numberOfTuples int) []string {
+	if tupleSize <= 0 {
+		return nil
+	}
+	var parts []string
+	for i := 0; i < numberOfTuples; i++ {
+		start := i * tupleSize
+		end := start + tupleSize
+		if end > len(hashHex) {
+			break
+		}
+		parts = append(parts, hashHex[start:end])
+	}
+	return parts
+}
+
+// leftTuples splits the first tupleSize*numberOfTuples characters of s into
+// numberOfTuples substrings of tupleSize characters each, left to right.
+// Callers are expected to have already padded s to at least that length.
+func leftTuples(s string, tupleSize, numberOfTuples int) []string {
+	if tupleSize <= 0 {
+		return nil
+	}
+	var parts []string
+	for i := 0; i < numberOfTuples; i++ {
+		start := i * tupleSize
+		end := start + tupleSize
+		if end > len(s) {
+			break
+		}
+		parts = append(parts, s[start:end])
+	}
+	return parts
+}
+
+// padTuples zero-pads s up to tupleSize*numberOfTuples characters, on the
+// side named by side ("left" or "right"), so that every object ID produces
+// the full number of tuples regardless of its length.
+func padTuples(s string, tupleSize, numberOfTuples int, side string) string {
+	total := tupleSize * numberOfTuples
+	if padLen := total - len(s); padLen > 0 {
+		pad := strings.Repeat("0", padLen)
+		if side == "right" {
+			return s + pad
+		}
+		return pad + s
+	}
+	return s
+}
+
+// reverseString reverses s by rune.
+func reverseString(s string) string {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r)
+}
+
+// omitPrefix strips everything up to and including the last occurrence of
+// delimiter from id. If delimiter is empty or not found, id is returned
+// unchanged.
+func omitPrefix(id, delimiter string) string {
+	if delimiter == "" {
+		return id
+	}
+	idx := strings.LastIndex(id, delimiter)
+	if idx < 0 {
+		return id
+	}
+	return id[idx+len(delimiter):]
+}
@@ -0,0 +1,80 @@
+package main
+
+import "testing"
+
+func TestLayoutExtensions(t *testing.T) {
+	cases := []struct {
+		name      string
+		layout    LayoutExtension
+		config    string
+		objectID  string
+		wantPath  string
+	}{
+		{
+			name:     "0002-flat-direct",
+			layout:   &flatDirectLayout{},
+			config:   `{}`,
+			objectID: "ark:123/abc",
+			wantPath: "ark:123/abc",
+		},
+		{
+			name:     "0003-hash-and-id-n-tuple",
+			layout:   &hashNTupleLayout{},
+			config:   `{"digestAlgorithm": "sha256", "tupleSize": 3, "numberOfTuples": 3}`,
+			objectID: "ark:123/abc",
+			wantPath: "a47/817/83d/ark:123/abc",
+		},
+		{
+			name:     "0004-hashed-n-tuple",
+			layout:   &hashedNTupleLayout{},
+			config:   `{"digestAlgorithm": "sha256", "tupleSize": 3, "numberOfTuples": 3}`,
+			objectID: "ark:123/abc",
+			wantPath: "a47/817/83d/a4781783dceceffe7af9af3fc4299cc6c93dc87754d6353d31a9e44e8a2838a0",
+		},
+		{
+			name:     "0006-flat-omit-prefix",
+			layout:   &flatOmitPrefixLayout{},
+			config:   `{"delimiter": ":"}`,
+			objectID: "urn:example:rocks:abc123",
+			wantPath: "abc123",
+		},
+		{
+			name:     "0007-n-tuple-omit-prefix",
+			layout:   &nTupleOmitPrefixLayout{},
+			config:   `{"delimiter": ":", "tupleSize": 2, "numberOfTuples": 2}`,
+			objectID: "urn:example:rocks:abc123",
+			wantPath: "ab/c1/abc123",
+		},
+		{
+			name:     "0007-n-tuple-omit-prefix-zero-padded",
+			layout:   &nTupleOmitPrefixLayout{},
+			config:   `{"delimiter": ":", "tupleSize": 3, "numberOfTuples": 3}`,
+			objectID: "urn:example:ab",
+			wantPath: "000/000/0ab/ab",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.layout.Configure([]byte(c.config)); err != nil {
+				t.Fatalf("Configure: %v", err)
+			}
+			if got := c.layout.ObjectPath(c.objectID); got != c.wantPath {
+				t.Errorf("ObjectPath(%q) = %q, want %q", c.objectID, got, c.wantPath)
+			}
+		})
+	}
+}
+
+func TestRegisterLayout(t *testing.T) {
+	const name = "test-custom-layout"
+	RegisterLayout(name, func() LayoutExtension { return &flatDirectLayout{} })
+
+	newLayout, ok := layoutRegistry[name]
+	if !ok {
+		t.Fatal("expected custom layout to be registered")
+	}
+	if got := newLayout().Name(); got != "0002-flat-direct-storage-layout" {
+		t.Errorf("got %q", got)
+	}
+}
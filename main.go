@@ -1,10 +1,17 @@
+// Command ocfl-fuse is the original S3-only OCFL mount tool. It predates
+// pkg/ocflfuse and hand-rolls its own inventory parsing and layout-extension
+// registry (see layout.go, storageroot.go) rather than building on the
+// ocfl-go-based implementation that pkg/ocflfuse and cmd/mount-ocfl now
+// share, which also covers local storage roots and an S3 gateway. That
+// split is tech debt, not a deliberate design choice: cmd/mount-ocfl is the
+// actively maintained front end, and this command should eventually be
+// rewritten as a thin wrapper around pkg/ocflfuse (or removed in favor of
+// cmd/mount-ocfl) instead of carrying a second, independently-maintained
+// OCFL implementation.
 package main
 
 import (
 	"context"
-	"crypto/sha256"
-	"crypto/sha512"
-	"encoding/hex"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -53,23 +60,29 @@ type OCFLLayout struct {
 	Description string `json:"description"`
 }
 
-// HashAndIDNTupleConfig represents the 0003 extension config
-type HashAndIDNTupleConfig struct {
-	ExtensionName   string `json:"extensionName"`
-	DigestAlgorithm string `json:"digestAlgorithm"`
-	TupleSize       int    `json:"tupleSize"`
-	NumberOfTuples  int    `json:"numberOfTuples"`
-}
-
 // S3Backend handles S3 operations
 type S3Backend struct {
-	client       *s3.Client
-	bucket       string
-	prefix       string
-	layoutConfig *HashAndIDNTupleConfig
+	client *s3.Client
+	bucket string
+	prefix string
+	layout LayoutExtension // nil means flat layout: ObjectPath is the identity
+
+	// blockCache, when non-nil, serves OCFLFile reads from a bounded,
+	// in-memory cache of blockSize-aligned blocks keyed by (S3 key, block
+	// index) instead of re-fetching the same bytes from S3 on every FUSE
+	// read. readAheadBlocks further blocks are fetched in the same ranged
+	// GetObject on a cache miss, to amortize request overhead on
+	// sequential reads.
+	blockCache      *blockCache
+	blockSize       int64
+	readAheadBlocks int64
 }
 
-func NewS3Backend(ctx context.Context, bucketPrefix string) (*S3Backend, error) {
+// NewS3Backend opens an S3 backend for bucketPrefix ("bucket" or
+// "bucket/prefix"). blockSize, cacheMaxBytes and readAheadBlocks configure
+// the read-ahead block cache used by OCFLFile.Read; blockSize <= 0 disables
+// caching entirely (every read issues its own ranged GetObject).
+func NewS3Backend(ctx context.Context, bucketPrefix string, blockSize, cacheMaxBytes, readAheadBlocks int64) (*S3Backend, error) {
 	parts := strings.SplitN(bucketPrefix, "/", 2)
 	bucket := parts[0]
 	prefix := ""
@@ -83,9 +96,14 @@ func NewS3Backend(ctx context.Context, bucketPrefix string) (*S3Backend, error)
 	}
 
 	backend := &S3Backend{
-		client: s3.NewFromConfig(cfg),
-		bucket: bucket,
-		prefix: prefix,
+		client:          s3.NewFromConfig(cfg),
+		bucket:          bucket,
+		prefix:          prefix,
+		blockSize:       blockSize,
+		readAheadBlocks: readAheadBlocks,
+	}
+	if blockSize > 0 {
+		backend.blockCache = newBlockCache(blockSize, cacheMaxBytes)
 	}
 
 	// Try to load layout configuration
@@ -96,8 +114,11 @@ func NewS3Backend(ctx context.Context, bucketPrefix string) (*S3Backend, error)
 	return backend, nil
 }
 
+// loadLayoutConfig reads ocfl_layout.json, looks its extension up in the
+// layout registry, and configures it from the extension's config.json.
+// Extensions with no registered implementation, or storage roots with no
+// ocfl_layout.json at all, fall back to a flat layout.
 func (s *S3Backend) loadLayoutConfig(ctx context.Context) error {
-	// Read ocfl_layout.json
 	layoutData, err := s.GetObject(ctx, "ocfl_layout.json")
 	if err != nil {
 		return fmt.Errorf("reading ocfl_layout.json: %w", err)
@@ -108,69 +129,48 @@ func (s *S3Backend) loadLayoutConfig(ctx context.Context) error {
 		return fmt.Errorf("parsing ocfl_layout.json: %w", err)
 	}
 
-	if layout.Extension == "0003-hash-and-id-n-tuple-storage-layout" {
-		// Read extension config
-		configPath := "extensions/0003-hash-and-id-n-tuple-storage-layout/config.json"
-		configData, err := s.GetObject(ctx, configPath)
-		if err != nil {
-			return fmt.Errorf("reading extension config: %w", err)
-		}
+	newLayout, ok := layoutRegistry[layout.Extension]
+	if !ok {
+		log.Printf("no registered layout for extension %q; using flat layout", layout.Extension)
+		return nil
+	}
 
-		var config HashAndIDNTupleConfig
-		if err := json.Unmarshal(configData, &config); err != nil {
-			return fmt.Errorf("parsing extension config: %w", err)
-		}
+	configPath := "extensions/" + layout.Extension + "/config.json"
+	configData, err := s.GetObject(ctx, configPath)
+	if err != nil {
+		return fmt.Errorf("reading extension config: %w", err)
+	}
 
-		s.layoutConfig = &config
-		log.Printf("Using 0003-hash-and-id-n-tuple-storage-layout: %s, tupleSize=%d, numberOfTuples=%d",
-			config.DigestAlgorithm, config.TupleSize, config.NumberOfTuples)
+	ext := newLayout()
+	if err := ext.Configure(configData); err != nil {
+		return fmt.Errorf("configuring %s layout: %w", layout.Extension, err)
 	}
+	s.layout = ext
+	log.Printf("Using %s layout", ext.Name())
 
 	return nil
 }
 
-// ObjectPath returns the storage path for an object ID based on the layout
+// ObjectPath returns the storage path for an object ID based on the
+// storage root's layout extension, or the object ID itself if the storage
+// root has no recognized layout.
 func (s *S3Backend) ObjectPath(objectID string) string {
-	if s.layoutConfig == nil {
-		// Flat layout - use object ID directly
+	if s.layout == nil {
 		return objectID
 	}
-
-	// Hash the object ID
-	var hashHex string
-	switch s.layoutConfig.DigestAlgorithm {
-	case "sha256":
-		hash := sha256.Sum256([]byte(objectID))
-		hashHex = hex.EncodeToString(hash[:])
-	case "sha512":
-		hash := sha512.Sum512([]byte(objectID))
-		hashHex = hex.EncodeToString(hash[:])
-	default:
-		// Fall back to flat layout
-		return objectID
-	}
-
-	// Build tuple path
-	var parts []string
-	for i := 0; i < s.layoutConfig.NumberOfTuples; i++ {
-		start := i * s.layoutConfig.TupleSize
-		end := start + s.layoutConfig.TupleSize
-		if end > len(hashHex) {
-			break
-		}
-		parts = append(parts, hashHex[start:end])
-	}
-	parts = append(parts, objectID)
-
-	return strings.Join(parts, "/")
+	return s.layout.ObjectPath(objectID)
 }
 
-func (s *S3Backend) GetObject(ctx context.Context, key string) ([]byte, error) {
-	fullKey := key
+// fullKey returns key's full S3 key, with the backend's prefix applied.
+func (s *S3Backend) fullKey(key string) string {
 	if s.prefix != "" {
-		fullKey = s.prefix + "/" + key
+		return s.prefix + "/" + key
 	}
+	return key
+}
 
+func (s *S3Backend) GetObject(ctx context.Context, key string) ([]byte, error) {
+	fullKey := s.fullKey(key)
 	resp, err := s.client.GetObject(ctx, &s3.GetObjectInput{
 		Bucket: &s.bucket,
 		Key:    &fullKey,
@@ -184,11 +184,7 @@ func (s *S3Backend) GetObject(ctx context.Context, key string) ([]byte, error) {
 }
 
 func (s *S3Backend) GetObjectReader(ctx context.Context, key string) (io.ReadCloser, int64, error) {
-	fullKey := key
-	if s.prefix != "" {
-		fullKey = s.prefix + "/" + key
-	}
-
+	fullKey := s.fullKey(key)
 	resp, err := s.client.GetObject(ctx, &s3.GetObjectInput{
 		Bucket: &s.bucket,
 		Key:    &fullKey,
@@ -217,33 +213,14 @@ type OCFLObject struct {
 	sizes map[string]int64
 }
 
-func NewOCFLObject(ctx context.Context, backend *S3Backend, objectID, version string) (*OCFLObject, error) {
-	// OCFL object path is derived from the object ID using the repository's layout
-	objectPath := backend.ObjectPath(objectID)
-	log.Printf("Object path for %q: %s", objectID, objectPath)
-
-	// Fetch the inventory
-	inventoryPath := objectPath + "/inventory.json"
-	data, err := backend.GetObject(ctx, inventoryPath)
-	if err != nil {
-		return nil, fmt.Errorf("fetching inventory: %w", err)
-	}
-
-	var inventory OCFLInventory
-	if err := json.Unmarshal(data, &inventory); err != nil {
-		return nil, fmt.Errorf("parsing inventory: %w", err)
-	}
-
-	// Use head version if not specified
-	if version == "" {
-		version = inventory.Head
-	}
-
-	// Validate version exists
-	versionData, ok := inventory.Versions[version]
+// buildFileMap resolves version's logical-path-to-content-path mapping from
+// inv's manifest and state. Content paths are relative to the storage root,
+// i.e. prefixed with objectPath.
+func buildFileMap(inv *OCFLInventory, objectPath, version string) (map[string]string, error) {
+	versionData, ok := inv.Versions[version]
 	if !ok {
 		var versions []string
-		for v := range inventory.Versions {
+		for v := range inv.Versions {
 			versions = append(versions, v)
 		}
 		sort.Strings(versions)
@@ -252,13 +229,12 @@ func NewOCFLObject(ctx context.Context, backend *S3Backend, objectID, version st
 
 	// Build reverse manifest lookup (digest -> content path)
 	digestToPath := make(map[string]string)
-	for digest, paths := range inventory.Manifest {
+	for digest, paths := range inv.Manifest {
 		if len(paths) > 0 {
 			digestToPath[digest] = paths[0]
 		}
 	}
 
-	// Build file map from version state
 	files := make(map[string]string)
 	for digest, logicalPaths := range versionData.State {
 		contentPath, ok := digestToPath[digest]
@@ -269,183 +245,107 @@ func NewOCFLObject(ctx context.Context, backend *S3Backend, objectID, version st
 			files[logicalPath] = objectPath + "/" + contentPath
 		}
 	}
-
-	log.Printf("Loaded OCFL object %q version %s with %d files", objectID, version, len(files))
-
-	return &OCFLObject{
-		backend:   backend,
-		objectID:  objectID,
-		version:   version,
-		inventory: &inventory,
-		files:     files,
-		sizes:     make(map[string]int64),
-	}, nil
+	return files, nil
 }
 
-// FUSE filesystem implementation
-
-type OCFLRoot struct {
-	fs.Inode
-	obj *OCFLObject
-}
-
-var _ = (fs.NodeOnAdder)((*OCFLRoot)(nil))
-
-func (r *OCFLRoot) OnAdd(ctx context.Context) {
-	// Build the directory tree from the file list
-	for logicalPath, contentPath := range r.obj.files {
-		// Create parent directories as needed
-		dir := &r.Inode
-		parts := strings.Split(logicalPath, "/")
-
-		for i, part := range parts[:len(parts)-1] {
-			child := dir.GetChild(part)
-			if child == nil {
-				dirNode := &OCFLDir{}
-				child = dir.NewPersistentInode(ctx, dirNode, fs.StableAttr{Mode: syscall.S_IFDIR})
-				dir.AddChild(part, child, false)
-			}
-			dir = child
-			_ = i
-		}
+func NewOCFLObject(ctx context.Context, backend *S3Backend, objectID, version string) (*OCFLObject, error) {
+	// OCFL object path is derived from the object ID using the repository's layout
+	objectPath := backend.ObjectPath(objectID)
+	log.Printf("Object path for %q: %s", objectID, objectPath)
 
-		// Add the file
-		filename := parts[len(parts)-1]
-		fileNode := &OCFLFile{
-			obj:         r.obj,
-			logicalPath: logicalPath,
-			contentPath: contentPath,
-		}
-		child := dir.NewPersistentInode(ctx, fileNode, fs.StableAttr{Mode: syscall.S_IFREG})
-		dir.AddChild(filename, child, false)
+	// Fetch the inventory
+	inventoryPath := objectPath + "/inventory.json"
+	data, err := backend.GetObject(ctx, inventoryPath)
+	if err != nil {
+		return nil, fmt.Errorf("fetching inventory: %w", err)
 	}
-}
 
-type OCFLDir struct {
-	fs.Inode
-}
-
-var _ = (fs.NodeGetattrer)((*OCFLDir)(nil))
-
-func (d *OCFLDir) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
-	out.Mode = 0755 | syscall.S_IFDIR
-	return 0
-}
-
-type OCFLFile struct {
-	fs.Inode
-	obj         *OCFLObject
-	logicalPath string
-	contentPath string
-	size        int64
-	sizeKnown   bool
-}
-
-var _ = (fs.NodeGetattrer)((*OCFLFile)(nil))
-var _ = (fs.NodeOpener)((*OCFLFile)(nil))
-var _ = (fs.NodeReader)((*OCFLFile)(nil))
-
-func (f *OCFLFile) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
-	if !f.sizeKnown {
-		// Fetch size from S3
-		_, size, err := f.obj.backend.GetObjectReader(ctx, f.contentPath)
-		if err != nil {
-			log.Printf("Error getting size for %s: %v", f.contentPath, err)
-			return syscall.EIO
-		}
-		f.size = size
-		f.sizeKnown = true
+	var inventory OCFLInventory
+	if err := json.Unmarshal(data, &inventory); err != nil {
+		return nil, fmt.Errorf("parsing inventory: %w", err)
 	}
-	out.Mode = 0644 | syscall.S_IFREG
-	out.Size = uint64(f.size)
-	return 0
-}
 
-func (f *OCFLFile) Open(ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
-	return &OCFLFileHandle{file: f}, fuse.FOPEN_KEEP_CACHE, 0
-}
-
-func (f *OCFLFile) Read(ctx context.Context, fh fs.FileHandle, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
-	reader, _, err := f.obj.backend.GetObjectReader(ctx, f.contentPath)
-	if err != nil {
-		log.Printf("Error reading %s: %v", f.contentPath, err)
-		return nil, syscall.EIO
-	}
-	defer reader.Close()
-
-	// Skip to offset
-	if off > 0 {
-		if _, err := io.CopyN(io.Discard, reader, off); err != nil {
-			if err == io.EOF {
-				return fuse.ReadResultData(nil), 0
-			}
-			log.Printf("Error seeking %s: %v", f.contentPath, err)
-			return nil, syscall.EIO
-		}
+	// Use head version if not specified
+	if version == "" {
+		version = inventory.Head
 	}
 
-	n, err := reader.Read(dest)
-	if err != nil && err != io.EOF {
-		log.Printf("Error reading %s: %v", f.contentPath, err)
-		return nil, syscall.EIO
+	files, err := buildFileMap(&inventory, objectPath, version)
+	if err != nil {
+		return nil, err
 	}
 
-	return fuse.ReadResultData(dest[:n]), 0
-}
+	log.Printf("Loaded OCFL object %q version %s with %d files", objectID, version, len(files))
 
-type OCFLFileHandle struct {
-	file *OCFLFile
+	return &OCFLObject{
+		backend:   backend,
+		objectID:  objectID,
+		version:   version,
+		inventory: &inventory,
+		files:     files,
+		sizes:     make(map[string]int64),
+	}, nil
 }
 
 func main() {
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s [options] <bucket/prefix> <object-id> <mountpoint>\n\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "Mount an OCFL object from S3 as a read-only filesystem.\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] <bucket/prefix> <mountpoint>\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Mount an OCFL storage root from S3 as a read-only filesystem.\n\n")
 		fmt.Fprintf(os.Stderr, "Arguments:\n")
 		fmt.Fprintf(os.Stderr, "  bucket/prefix   S3 bucket and optional prefix (e.g., mybucket/ocfl-root)\n")
-		fmt.Fprintf(os.Stderr, "  object-id       OCFL object identifier\n")
 		fmt.Fprintf(os.Stderr, "  mountpoint      Local directory to mount the filesystem\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
 	}
 
-	version := flag.String("version", "", "Object version to mount (default: head/latest)")
+	objectID := flag.String("object", "", "Mount only this OCFL object, instead of browsing the whole storage root")
+	version := flag.String("version", "", "Object version to mount when -object is set (default: head/latest)")
+	blockSize := flag.Int64("block-size", 1<<20, "Block size for S3 range reads and cache entries, in bytes (0 disables caching)")
+	cacheSize := flag.Int64("cache-size", 256<<20, "Maximum size of the in-memory block cache, in bytes")
+	readAhead := flag.Int64("read-ahead", 3, "Number of additional blocks to fetch past a cache miss, for sequential reads")
 	debug := flag.Bool("debug", false, "Enable FUSE debug output")
 	flag.Parse()
 
-	if flag.NArg() != 3 {
+	if flag.NArg() != 2 {
 		flag.Usage()
 		os.Exit(1)
 	}
 
 	bucketPrefix := flag.Arg(0)
-	objectID := flag.Arg(1)
-	mountpoint := flag.Arg(2)
+	mountpoint := flag.Arg(1)
 
 	ctx := context.Background()
 
 	// Create S3 backend
-	backend, err := NewS3Backend(ctx, bucketPrefix)
+	backend, err := NewS3Backend(ctx, bucketPrefix, *blockSize, *cacheSize, *readAhead)
 	if err != nil {
 		log.Fatalf("Failed to create S3 backend: %v", err)
 	}
 
-	// Load OCFL object
-	obj, err := NewOCFLObject(ctx, backend, objectID, *version)
-	if err != nil {
-		log.Fatalf("Failed to load OCFL object: %v", err)
-	}
-
 	// Create mountpoint if it doesn't exist
 	if err := os.MkdirAll(mountpoint, 0755); err != nil {
 		log.Fatalf("Failed to create mountpoint: %v", err)
 	}
 
-	// Mount the filesystem
-	root := &OCFLRoot{obj: obj}
+	var root fs.InodeEmbedder
+	fsName := "ocfl-root"
+	if *objectID != "" {
+		// Load a single OCFL object, preserving the filesystem's original
+		// single-object behavior.
+		obj, err := NewOCFLObject(ctx, backend, *objectID, *version)
+		if err != nil {
+			log.Fatalf("Failed to load OCFL object: %v", err)
+		}
+		root = &OCFLRoot{backend: backend, files: toS3Files(backend, obj.files)}
+		fsName = "ocfl-" + path.Base(*objectID)
+	} else {
+		// Browse the whole storage root, lazily discovering objects.
+		root = &storageRootDir{backend: backend}
+	}
+
 	opts := &fs.Options{
 		MountOptions: fuse.MountOptions{
-			FsName: "ocfl-" + path.Base(objectID),
+			FsName: fsName,
 			Name:   "ocfl",
 			Debug:  *debug,
 		},
@@ -456,7 +356,11 @@ func main() {
 		log.Fatalf("Failed to mount: %v", err)
 	}
 
-	log.Printf("Mounted OCFL object %q version %s at %s", objectID, obj.version, mountpoint)
+	if *objectID != "" {
+		log.Printf("Mounted OCFL object %q at %s", *objectID, mountpoint)
+	} else {
+		log.Printf("Mounted OCFL storage root %q at %s", bucketPrefix, mountpoint)
+	}
 	log.Printf("Press Ctrl+C to unmount")
 
 	// Handle signals for clean unmount
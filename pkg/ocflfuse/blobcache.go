@@ -0,0 +1,172 @@
+package ocflfuse
+
+import (
+	"container/list"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DefaultChunkSize is the chunk size used to fetch and cache S3 content
+// when Options.ChunkSize is unset.
+const DefaultChunkSize = 4 << 20 // 4 MiB
+
+// BlobCache caches byte ranges of OCFL object content, addressed by OCFL
+// digest. Because OCFL content is content-addressed and immutable, a cache
+// entry for a digest is valid forever and never needs invalidating.
+type BlobCache interface {
+	// Get copies cached bytes for [off, off+len(p)) of digest's content into
+	// p and reports whether the full range was available.
+	Get(digest string, off int64, p []byte) (n int, ok bool)
+	// Put stores data as the bytes of digest's content starting at off.
+	Put(digest string, off int64, data []byte) error
+}
+
+// DiskBlobCacheOptions configures NewDiskBlobCache.
+type DiskBlobCacheOptions struct {
+	// Dir is the cache directory; it is created if missing.
+	Dir string
+	// MaxBytes bounds the cache's approximate on-disk size. Least-recently-used
+	// digests are evicted once it is exceeded. Zero means unbounded.
+	MaxBytes int64
+	// ChunkSize is the granularity at which cached ranges are tracked; it
+	// should match the chunk size used to populate the cache. Zero uses
+	// DefaultChunkSize.
+	ChunkSize int64
+}
+
+// diskBlobCache is the default BlobCache: one file per digest under Dir,
+// with cached byte ranges tracked at ChunkSize granularity.
+type diskBlobCache struct {
+	dir       string
+	maxBytes  int64
+	chunkSize int64
+
+	mu      sync.Mutex
+	lru     *list.List // of *blobCacheEntry, most recently used at the front
+	entries map[string]*blobCacheEntry
+	size    int64
+}
+
+type blobCacheEntry struct {
+	digest string
+	file   *os.File
+	chunks map[int64]bool // populated chunk indexes
+	elem   *list.Element
+}
+
+// NewDiskBlobCache returns a BlobCache backed by a directory on disk.
+func NewDiskBlobCache(opts DiskBlobCacheOptions) (BlobCache, error) {
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating cache dir: %w", err)
+	}
+	return &diskBlobCache{
+		dir:       opts.Dir,
+		maxBytes:  opts.MaxBytes,
+		chunkSize: chunkSize,
+		lru:       list.New(),
+		entries:   make(map[string]*blobCacheEntry),
+	}, nil
+}
+
+func (c *diskBlobCache) Get(digest string, off int64, p []byte) (int, bool) {
+	c.mu.Lock()
+	e, ok := c.entries[digest]
+	if ok {
+		c.lru.MoveToFront(e.elem)
+		ok = e.covers(off, int64(len(p)), c.chunkSize)
+	}
+	c.mu.Unlock()
+	if !ok {
+		return 0, false
+	}
+
+	n, err := e.file.ReadAt(p, off)
+	if err != nil && err != io.EOF {
+		return 0, false
+	}
+	return n, true
+}
+
+func (c *diskBlobCache) Put(digest string, off int64, data []byte) error {
+	e, err := c.entry(digest)
+	if err != nil {
+		return err
+	}
+	if _, err := e.file.WriteAt(data, off); err != nil {
+		return err
+	}
+
+	first := off / c.chunkSize
+	last := (off + int64(len(data)) - 1) / c.chunkSize
+	c.mu.Lock()
+	for i := first; i <= last; i++ {
+		if !e.chunks[i] {
+			e.chunks[i] = true
+			c.size += c.chunkSize
+		}
+	}
+	c.mu.Unlock()
+
+	c.evict()
+	return nil
+}
+
+// entry returns the cache entry for digest, creating its backing file if
+// this is the first time digest has been seen.
+func (c *diskBlobCache) entry(digest string) (*blobCacheEntry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[digest]; ok {
+		c.lru.MoveToFront(e.elem)
+		return e, nil
+	}
+
+	f, err := os.OpenFile(filepath.Join(c.dir, digest), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening cache file for digest %s: %w", digest, err)
+	}
+	e := &blobCacheEntry{digest: digest, file: f, chunks: make(map[int64]bool)}
+	e.elem = c.lru.PushFront(e)
+	c.entries[digest] = e
+	return e, nil
+}
+
+// evict removes least-recently-used entries until the cache fits MaxBytes.
+func (c *diskBlobCache) evict() {
+	if c.maxBytes <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.size > c.maxBytes {
+		back := c.lru.Back()
+		if back == nil {
+			return
+		}
+		e := back.Value.(*blobCacheEntry)
+		c.lru.Remove(back)
+		delete(c.entries, e.digest)
+		c.size -= int64(len(e.chunks)) * c.chunkSize
+		e.file.Close()
+		os.Remove(e.file.Name())
+	}
+}
+
+// covers reports whether every chunk touching [off, off+n) has been cached.
+func (e *blobCacheEntry) covers(off, n, chunkSize int64) bool {
+	first := off / chunkSize
+	last := (off + n - 1) / chunkSize
+	for i := first; i <= last; i++ {
+		if !e.chunks[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+package ocflfuse
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestDiskBlobCache(t *testing.T) {
+	cache, err := NewDiskBlobCache(DiskBlobCacheOptions{
+		Dir:       t.TempDir(),
+		ChunkSize: 8,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const digest = "abc123"
+	buf := make([]byte, 4)
+	if _, ok := cache.Get(digest, 0, buf); ok {
+		t.Fatal("expected miss before any Put")
+	}
+
+	if err := cache.Put(digest, 0, []byte("hello world")); err != nil {
+		t.Fatal(err)
+	}
+
+	n, ok := cache.Get(digest, 0, buf)
+	if !ok {
+		t.Fatal("expected hit after Put")
+	}
+	if got := string(buf[:n]); got != "hell" {
+		t.Errorf("got %q, want %q", got, "hell")
+	}
+
+	// A range beyond what was cached is still a miss, even though the
+	// digest is known.
+	if _, ok := cache.Get(digest, 100, buf); ok {
+		t.Error("expected miss for uncached range")
+	}
+}
+
+func TestDiskBlobCacheEviction(t *testing.T) {
+	cache, err := NewDiskBlobCache(DiskBlobCacheOptions{
+		Dir:       t.TempDir(),
+		ChunkSize: 8,
+		MaxBytes:  8,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cache.Put("a", 0, []byte("aaaaaaaa")); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Put("b", 0, []byte("bbbbbbbb")); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 8)
+	if _, ok := cache.Get("a", 0, buf); ok {
+		t.Error("expected digest a to be evicted once b was cached")
+	}
+	if _, ok := cache.Get("b", 0, buf); !ok {
+		t.Error("expected digest b to still be cached")
+	}
+}
+
+// TestDiskBlobCacheConcurrent exercises concurrent Get/Put on the same
+// digest, the pattern the kernel produces for concurrent FUSE reads. Run
+// with -race: a Get reading the covered-chunks map outside Put's lock would
+// report a data race here.
+func TestDiskBlobCacheConcurrent(t *testing.T) {
+	cache, err := NewDiskBlobCache(DiskBlobCacheOptions{
+		Dir:       t.TempDir(),
+		ChunkSize: 8,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const digest = "concurrent"
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func(off int64) {
+			defer wg.Done()
+			_ = cache.Put(digest, off*8, []byte("12345678"))
+		}(int64(i))
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, 8)
+			cache.Get(digest, 0, buf)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,192 @@
+package ocflfuse
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	ocfl "github.com/srerickson/ocfl-go"
+)
+
+// This file exposes the object/content resolution that NewObjectFS and
+// NewStorageRoot build their FUSE trees from, independent of FUSE, so other
+// front ends (e.g. an S3-compatible HTTP gateway) can serve the same
+// logical view without depending on fs.InodeEmbedder.
+
+// FileInfo describes the content backing a logical path in a resolved
+// object version.
+type FileInfo struct {
+	LogicalPath string
+	Digest      string
+	// Size is the content's byte length, or 0 if it isn't known from the
+	// inventory and must be learned by stating the backend.
+	Size int64
+}
+
+// Backend is a storage root opened for content access, shared by the FUSE
+// and HTTP gateway front ends.
+type Backend struct {
+	b *backend
+}
+
+// OpenBackend opens storageRoot for resolving objects against.
+func OpenBackend(ctx context.Context, storageRoot string, opts Options) (*Backend, error) {
+	b, err := openBackend(ctx, storageRoot, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Backend{b: b}, nil
+}
+
+// Spec returns the OCFL storage root's declared spec version.
+func (be *Backend) Spec() string { return string(be.b.root.Spec()) }
+
+// Layout describes the storage root's layout extension, if any.
+func (be *Backend) Layout() string { return layoutString(be.b.root) }
+
+// Objects iterates every object ID in the storage root.
+func (be *Backend) Objects(ctx context.Context) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		for obj, err := range be.b.root.Objects(ctx) {
+			if err != nil {
+				if !yield("", err) {
+					return
+				}
+				continue
+			}
+			if !yield(obj.ID(), nil) {
+				return
+			}
+		}
+	}
+}
+
+// ResolveObject resolves objectID's version (pass "" for HEAD) and returns
+// its logical file map for reading.
+func (be *Backend) ResolveObject(ctx context.Context, objectID, version string) (*ResolvedObject, error) {
+	obj, err := be.b.root.NewObject(ctx, objectID, ocfl.ObjectMustExist())
+	if err != nil {
+		return nil, fmt.Errorf("loading OCFL object: %w", err)
+	}
+	ver, err := resolveVersion(obj, version)
+	if err != nil {
+		return nil, err
+	}
+	files, err := buildFileMap(obj, ver)
+	if err != nil {
+		return nil, err
+	}
+	return &ResolvedObject{backend: be.b, obj: obj, ver: ver, files: files}, nil
+}
+
+// EncodeObjectID and DecodeObjectID convert between an OCFL object ID and a
+// reversible name safe for use as a filesystem or URL path segment.
+func EncodeObjectID(objectID string) string      { return encodeObjectDirName(objectID) }
+func DecodeObjectID(name string) (string, error) { return decodeObjectDirName(name) }
+
+// ResolvedObject is a single OCFL object version, resolved for content
+// access.
+type ResolvedObject struct {
+	backend *backend
+	obj     *ocfl.Object
+	ver     *ocfl.ObjectVersion
+	files   map[string]contentRef
+}
+
+// VersionID returns the resolved version's number, e.g. "v3".
+func (r *ResolvedObject) VersionID() string { return r.ver.VNum().String() }
+
+// Versions lists every version of the object, oldest first.
+func (r *ResolvedObject) Versions() []string {
+	var out []string
+	for _, v := range objectVersions(r.obj) {
+		out = append(out, v.VNum().String())
+	}
+	return out
+}
+
+// Files lists every logical path in this version.
+func (r *ResolvedObject) Files() map[string]FileInfo {
+	out := make(map[string]FileInfo, len(r.files))
+	for p, ref := range r.files {
+		out[p] = FileInfo{LogicalPath: p, Digest: ref.Digest, Size: ref.Size}
+	}
+	return out
+}
+
+// Open returns logicalPath's content, restricted to the inclusive byte
+// range [start, end] (end < 0 means "to EOF"), along with its total size.
+// It reuses the same S3/local read paths as FUSE reads.
+func (r *ResolvedObject) Open(ctx context.Context, logicalPath string, start, end int64) (io.ReadCloser, int64, error) {
+	ref, ok := r.files[logicalPath]
+	if !ok {
+		return nil, 0, fmt.Errorf("%s: not found", logicalPath)
+	}
+	return r.backend.openRange(ctx, ref, start, end)
+}
+
+// openRange opens ref's content, restricted to the inclusive byte range
+// [start, end] (end < 0 means "to EOF"), and returns it along with its
+// total size.
+func (b *backend) openRange(ctx context.Context, ref contentRef, start, end int64) (io.ReadCloser, int64, error) {
+	if b.s3Client != nil {
+		return b.openS3Range(ctx, ref, start, end)
+	}
+	return b.openLocalRange(ref, start, end)
+}
+
+func (b *backend) openS3Range(ctx context.Context, ref contentRef, start, end int64) (io.ReadCloser, int64, error) {
+	input := &s3.GetObjectInput{Bucket: &b.bucket, Key: &ref.Path}
+	if start > 0 || end >= 0 {
+		rangeHeader := fmt.Sprintf("bytes=%d-", start)
+		if end >= 0 {
+			rangeHeader = fmt.Sprintf("bytes=%d-%d", start, end)
+		}
+		input.Range = &rangeHeader
+	}
+	resp, err := b.s3Client.GetObject(ctx, input)
+	if err != nil {
+		return nil, 0, fmt.Errorf("fetching %s: %w", ref.Path, err)
+	}
+	size := ref.Size
+	if size == 0 && resp.ContentLength != nil {
+		size = *resp.ContentLength
+	}
+	return resp.Body, size, nil
+}
+
+func (b *backend) openLocalRange(ref contentRef, start, end int64) (io.ReadCloser, int64, error) {
+	path := filepath.Join(b.localRoot, filepath.FromSlash(ref.Path))
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("opening %s: %w", path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, fmt.Errorf("stating %s: %w", path, err)
+	}
+	size := info.Size()
+	if end < 0 || end >= size {
+		end = size - 1
+	}
+	if _, err := f.Seek(start, io.SeekStart); err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	return &limitedFile{r: io.LimitReader(f, end-start+1), f: f}, size, nil
+}
+
+// limitedFile adapts a limited read over an *os.File into an io.ReadCloser
+// that still closes the underlying file.
+type limitedFile struct {
+	r io.Reader
+	f *os.File
+}
+
+func (l *limitedFile) Read(p []byte) (int, error) { return l.r.Read(p) }
+func (l *limitedFile) Close() error                { return l.f.Close() }
@@ -0,0 +1,486 @@
+package ocflfuse
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+	ocfl "github.com/srerickson/ocfl-go"
+)
+
+// fileNodeCreator creates a persistent FUSE inode for a file.
+type fileNodeCreator func(ctx context.Context, parent *fs.Inode, ref contentRef) *fs.Inode
+
+// buildFuseTree populates a FUSE inode tree from logical paths using the given
+// file node creator. This is shared between S3 and local backends.
+func buildFuseTree(ctx context.Context, root *fs.Inode, files map[string]contentRef, newFile fileNodeCreator) {
+	for logicalPath, ref := range files {
+		dir := root
+		parts := strings.Split(logicalPath, "/")
+
+		// Create parent directories
+		for _, part := range parts[:len(parts)-1] {
+			child := dir.GetChild(part)
+			if child == nil {
+				dirNode := &ocflDir{}
+				child = dir.NewPersistentInode(ctx, dirNode, fs.StableAttr{Mode: syscall.S_IFDIR})
+				dir.AddChild(part, child, false)
+			}
+			dir = child
+		}
+
+		// Add file node
+		filename := parts[len(parts)-1]
+		child := newFile(ctx, dir, ref)
+		dir.AddChild(filename, child, false)
+	}
+}
+
+// digestXattrName is the extended attribute exposing a file's OCFL digest.
+const digestXattrName = "user.ocfl.digest"
+
+// getDigestXattr implements NodeGetxattrer for a file backed by digest.
+func getDigestXattr(digest, attr string, dest []byte) (uint32, syscall.Errno) {
+	if attr != digestXattrName {
+		return 0, syscall.ENODATA
+	}
+	if len(dest) < len(digest) {
+		return uint32(len(digest)), syscall.ERANGE
+	}
+	return uint32(copy(dest, digest)), 0
+}
+
+// listDigestXattr implements NodeListxattrer for a file exposing digestXattrName.
+func listDigestXattr(digest string, dest []byte) (uint32, syscall.Errno) {
+	name := digestXattrName + "\x00"
+	if len(dest) < len(name) {
+		return uint32(len(name)), syscall.ERANGE
+	}
+	return uint32(copy(dest, name)), 0
+}
+
+// ocflDir is a read-only directory node in the FUSE tree.
+type ocflDir struct {
+	fs.Inode
+}
+
+var _ = (fs.NodeGetattrer)((*ocflDir)(nil))
+
+func (d *ocflDir) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
+	out.Mode = 0555 | syscall.S_IFDIR
+	return 0
+}
+
+// --- S3 backend ---
+
+// s3Root is the FUSE root node for S3-backed OCFL objects.
+type s3Root struct {
+	fs.Inode
+	backend *backend
+	files   map[string]contentRef // logicalPath -> content
+}
+
+var _ = (fs.NodeOnAdder)((*s3Root)(nil))
+
+func (r *s3Root) OnAdd(ctx context.Context) {
+	buildFuseTree(ctx, &r.Inode, r.files, func(ctx context.Context, parent *fs.Inode, ref contentRef) *fs.Inode {
+		return parent.NewPersistentInode(ctx, r.backend.newFileNode(ref), fs.StableAttr{Mode: syscall.S_IFREG})
+	})
+}
+
+// s3File is a file node backed by an S3 object. Reads are served through
+// cache, a read-through content cache, when one is configured; otherwise
+// each read issues its own ranged GetObject.
+type s3File struct {
+	fs.Inode
+	s3Client  *s3.Client
+	bucket    string
+	s3Key     string
+	digest    string    // OCFL digest of this content, used as the cache key
+	size      int64     // from the inventory, 0 if unknown
+	cache     BlobCache // optional; nil disables caching
+	chunkSize int64     // chunk size to fetch/cache on a miss
+
+	sizeOnce sync.Once
+	sizeErr  error
+}
+
+var _ = (fs.NodeGetattrer)((*s3File)(nil))
+var _ = (fs.NodeGetxattrer)((*s3File)(nil))
+var _ = (fs.NodeListxattrer)((*s3File)(nil))
+var _ = (fs.NodeOpener)((*s3File)(nil))
+var _ = (fs.NodeReader)((*s3File)(nil))
+
+// fetchSize issues a HeadObject to learn the object's size, for content
+// whose inventory doesn't record a size (see manifestSizes).
+func (f *s3File) fetchSize(ctx context.Context) (int64, error) {
+	f.sizeOnce.Do(func() {
+		resp, err := f.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket: &f.bucket,
+			Key:    &f.s3Key,
+		})
+		if err != nil {
+			f.sizeErr = err
+			return
+		}
+		if resp.ContentLength != nil {
+			f.size = *resp.ContentLength
+		}
+	})
+	return f.size, f.sizeErr
+}
+
+func (f *s3File) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
+	size := f.size
+	if size == 0 {
+		var err error
+		size, err = f.fetchSize(ctx)
+		if err != nil {
+			log.Printf("HeadObject error for %s: %v", f.s3Key, err)
+			return syscall.EIO
+		}
+	}
+	out.Mode = 0444 | syscall.S_IFREG
+	out.Size = uint64(size)
+	return 0
+}
+
+// Getxattr exposes the OCFL digest backing this file as user.ocfl.digest, so
+// downstream tooling can verify content without re-reading it.
+func (f *s3File) Getxattr(ctx context.Context, attr string, dest []byte) (uint32, syscall.Errno) {
+	return getDigestXattr(f.digest, attr, dest)
+}
+
+func (f *s3File) Listxattr(ctx context.Context, dest []byte) (uint32, syscall.Errno) {
+	return listDigestXattr(f.digest, dest)
+}
+
+func (f *s3File) Open(ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
+	return nil, fuse.FOPEN_KEEP_CACHE, 0
+}
+
+func (f *s3File) Read(ctx context.Context, fh fs.FileHandle, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
+	if f.cache != nil && f.digest != "" {
+		return f.readCached(ctx, dest, off)
+	}
+	data, err := f.getRange(ctx, off, off+int64(len(dest))-1)
+	if err != nil {
+		return nil, syscall.EIO
+	}
+	return fuse.ReadResultData(data), 0
+}
+
+// readCached serves a read from cache, falling back to fetching and caching
+// a chunkSize-aligned chunk of the object from S3 on a miss.
+func (f *s3File) readCached(ctx context.Context, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
+	if n, ok := f.cache.Get(f.digest, off, dest); ok {
+		return fuse.ReadResultData(dest[:n]), 0
+	}
+
+	chunkSize := f.chunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	chunkStart := (off / chunkSize) * chunkSize
+	chunk, err := f.getRange(ctx, chunkStart, chunkStart+chunkSize-1)
+	if err != nil {
+		return nil, syscall.EIO
+	}
+	if err := f.cache.Put(f.digest, chunkStart, chunk); err != nil {
+		log.Printf("caching digest %s: %v", f.digest, err)
+	}
+
+	relOff := off - chunkStart
+	if relOff < 0 || relOff > int64(len(chunk)) {
+		return nil, syscall.EIO
+	}
+	n := copy(dest, chunk[relOff:])
+	return fuse.ReadResultData(dest[:n]), 0
+}
+
+// getRange fetches bytes [start, end] of the S3 object.
+func (f *s3File) getRange(ctx context.Context, start, end int64) ([]byte, error) {
+	rangeHeader := fmt.Sprintf("bytes=%d-%d", start, end)
+	resp, err := f.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &f.bucket,
+		Key:    &f.s3Key,
+		Range:  &rangeHeader,
+	})
+	if err != nil {
+		log.Printf("GetObject range error for %s: %v", f.s3Key, err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Printf("Read error for %s: %v", f.s3Key, err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// --- Local filesystem backend ---
+
+// localRoot is the FUSE root node for locally-stored OCFL objects.
+type localRoot struct {
+	fs.Inode
+	backend *backend
+	files   map[string]contentRef // logicalPath -> content
+}
+
+var _ = (fs.NodeOnAdder)((*localRoot)(nil))
+
+func (r *localRoot) OnAdd(ctx context.Context) {
+	buildFuseTree(ctx, &r.Inode, r.files, func(ctx context.Context, parent *fs.Inode, ref contentRef) *fs.Inode {
+		return parent.NewPersistentInode(ctx, r.backend.newFileNode(ref), fs.StableAttr{Mode: syscall.S_IFREG})
+	})
+}
+
+// localFile is a file node backed by a local file.
+type localFile struct {
+	fs.Inode
+	path   string // absolute path on disk
+	digest string // OCFL digest of this content
+}
+
+var _ = (fs.NodeGetattrer)((*localFile)(nil))
+var _ = (fs.NodeGetxattrer)((*localFile)(nil))
+var _ = (fs.NodeListxattrer)((*localFile)(nil))
+var _ = (fs.NodeOpener)((*localFile)(nil))
+
+func (f *localFile) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		log.Printf("stat error for %s: %v", f.path, err)
+		return syscall.EIO
+	}
+	out.Mode = 0444 | syscall.S_IFREG
+	out.Size = uint64(info.Size())
+	return 0
+}
+
+// Getxattr exposes the OCFL digest backing this file as user.ocfl.digest, so
+// downstream tooling can verify content without re-reading it.
+func (f *localFile) Getxattr(ctx context.Context, attr string, dest []byte) (uint32, syscall.Errno) {
+	return getDigestXattr(f.digest, attr, dest)
+}
+
+func (f *localFile) Listxattr(ctx context.Context, dest []byte) (uint32, syscall.Errno) {
+	return listDigestXattr(f.digest, dest)
+}
+
+func (f *localFile) Open(ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
+	fh, err := os.Open(f.path)
+	if err != nil {
+		log.Printf("open error for %s: %v", f.path, err)
+		return nil, 0, syscall.EIO
+	}
+	return &localFileHandle{file: fh}, fuse.FOPEN_KEEP_CACHE, 0
+}
+
+// localFileHandle holds an open file descriptor for a local file.
+type localFileHandle struct {
+	file *os.File
+}
+
+var _ = (fs.FileReader)((*localFileHandle)(nil))
+var _ = (fs.FileReleaser)((*localFileHandle)(nil))
+
+func (fh *localFileHandle) Read(ctx context.Context, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
+	n, err := fh.file.ReadAt(dest, off)
+	if err != nil && err != io.EOF {
+		log.Printf("read error for %s: %v", fh.file.Name(), err)
+		return nil, syscall.EIO
+	}
+	return fuse.ReadResultData(dest[:n]), 0
+}
+
+func (fh *localFileHandle) Release(ctx context.Context) syscall.Errno {
+	if err := fh.file.Close(); err != nil {
+		log.Printf("close error for %s: %v", fh.file.Name(), err)
+		return syscall.EIO
+	}
+	return 0
+}
+
+// --- Storage root browsing ---
+
+// storageRootDir is the FUSE root node for browsing an entire OCFL storage
+// root. Each child is an object directory, looked up and listed lazily so
+// that mounting a repository never requires loading every object's
+// inventory up front.
+type storageRootDir struct {
+	fs.Inode
+	backend *backend
+}
+
+var _ = (fs.NodeLookuper)((*storageRootDir)(nil))
+var _ = (fs.NodeReaddirer)((*storageRootDir)(nil))
+
+// encodeObjectDirName turns an OCFL object ID into a filesystem-safe,
+// reversible directory name.
+func encodeObjectDirName(objectID string) string {
+	return url.PathEscape(objectID)
+}
+
+// decodeObjectDirName reverses encodeObjectDirName.
+func decodeObjectDirName(name string) (string, error) {
+	return url.PathUnescape(name)
+}
+
+func (d *storageRootDir) Lookup(ctx context.Context, name string, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+	objectID, err := decodeObjectDirName(name)
+	if err != nil {
+		return nil, syscall.ENOENT
+	}
+	obj, err := d.backend.root.NewObject(ctx, objectID, ocfl.ObjectMustExist())
+	if err != nil {
+		return nil, syscall.ENOENT
+	}
+	child := &objectDir{backend: d.backend, obj: obj}
+	return d.NewInode(ctx, child, fs.StableAttr{Mode: syscall.S_IFDIR}), 0
+}
+
+func (d *storageRootDir) Readdir(ctx context.Context) (fs.DirStream, syscall.Errno) {
+	var entries []fuse.DirEntry
+	for obj, err := range d.backend.root.Objects(ctx) {
+		if err != nil {
+			log.Printf("listing objects: %v", err)
+			continue
+		}
+		entries = append(entries, fuse.DirEntry{
+			Name: encodeObjectDirName(obj.ID()),
+			Mode: syscall.S_IFDIR,
+		})
+	}
+	return fs.NewListDirStream(entries), 0
+}
+
+// objectDir is the FUSE directory for a single object found while browsing
+// a storage root. It exposes the object's HEAD state at the top level and
+// every version under a `.versions` directory, built the first time the
+// directory is entered.
+type objectDir struct {
+	fs.Inode
+	backend *backend
+	obj     *ocfl.Object
+}
+
+var _ = (fs.NodeOnAdder)((*objectDir)(nil))
+
+func (d *objectDir) OnAdd(ctx context.Context) {
+	head, err := resolveVersion(d.obj, "")
+	if err != nil {
+		log.Printf("resolving HEAD version for %q: %v", d.obj.ID(), err)
+		return
+	}
+	if err := buildObjectTree(ctx, &d.Inode, d.backend, d.obj, head); err != nil {
+		log.Printf("building tree for %q: %v", d.obj.ID(), err)
+	}
+}
+
+// objectVersionsRoot is the FUSE root node for a single object mounted with
+// Options.AllVersions: top is exposed at the root, and every version of obj
+// is additionally exposed under `.versions`.
+type objectVersionsRoot struct {
+	fs.Inode
+	backend *backend
+	obj     *ocfl.Object
+	top     *ocfl.ObjectVersion
+}
+
+var _ = (fs.NodeOnAdder)((*objectVersionsRoot)(nil))
+
+func (r *objectVersionsRoot) OnAdd(ctx context.Context) {
+	if err := buildObjectTree(ctx, &r.Inode, r.backend, r.obj, r.top); err != nil {
+		log.Printf("building tree for %q: %v", r.obj.ID(), err)
+	}
+}
+
+// buildObjectTree populates root with top's logical file tree, plus a
+// `.versions` directory holding every version of obj. File inodes are
+// deduplicated by OCFL digest across top and every version, so content
+// unchanged between versions is backed by a single inode.
+func buildObjectTree(ctx context.Context, root *fs.Inode, b *backend, obj *ocfl.Object, top *ocfl.ObjectVersion) error {
+	cache := &digestInodeCache{}
+	newFile := func(ctx context.Context, parent *fs.Inode, ref contentRef) *fs.Inode {
+		return parent.NewPersistentInode(ctx, b.newFileNode(ref), fs.StableAttr{Mode: syscall.S_IFREG})
+	}
+
+	if err := buildVersionTree(ctx, root, obj, top, cache, newFile); err != nil {
+		return fmt.Errorf("building tree for version %s: %w", top.VNum(), err)
+	}
+
+	versions := root.NewPersistentInode(ctx, &ocflDir{}, fs.StableAttr{Mode: syscall.S_IFDIR})
+	for _, ver := range objectVersions(obj) {
+		verDir := versions.NewPersistentInode(ctx, &ocflDir{}, fs.StableAttr{Mode: syscall.S_IFDIR})
+		if err := buildVersionTree(ctx, verDir, obj, ver, cache, newFile); err != nil {
+			return fmt.Errorf("building tree for version %s: %w", ver.VNum(), err)
+		}
+		versions.AddChild(ver.VNum().String(), verDir, false)
+	}
+	root.AddChild(".versions", versions, false)
+	return nil
+}
+
+// digestInodeCache deduplicates FUSE file inodes by OCFL digest, so content
+// shared across multiple versions of an object is backed by a single inode
+// (and, for S3/local files, a single cached size lookup) rather than one
+// per version.
+type digestInodeCache struct {
+	mu    sync.Mutex
+	nodes map[string]*fs.Inode
+}
+
+func (c *digestInodeCache) getOrCreate(ctx context.Context, owner *fs.Inode, digest string, ref contentRef, newFile fileNodeCreator) *fs.Inode {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.nodes == nil {
+		c.nodes = make(map[string]*fs.Inode)
+	}
+	if node, ok := c.nodes[digest]; ok {
+		return node
+	}
+	node := newFile(ctx, owner, ref)
+	c.nodes[digest] = node
+	return node
+}
+
+// buildVersionTree populates dir with ver's logical file tree, reusing file
+// inodes across calls that share cache for identical digests.
+func buildVersionTree(ctx context.Context, dir *fs.Inode, obj *ocfl.Object, ver *ocfl.ObjectVersion, cache *digestInodeCache, newFile fileNodeCreator) error {
+	manifest := obj.Manifest()
+	objPath := obj.Path()
+	sizes := manifestSizes(obj)
+	for logicalPath, digest := range ver.State().Paths() {
+		contentPaths := manifest[digest]
+		if len(contentPaths) == 0 {
+			return fmt.Errorf("missing manifest entry for digest %s", digest)
+		}
+		ref := contentRef{Path: objPath + "/" + contentPaths[0], Digest: digest, Size: sizes[digest]}
+		node := cache.getOrCreate(ctx, dir, digest, ref, newFile)
+
+		parent := dir
+		parts := strings.Split(logicalPath, "/")
+		for _, part := range parts[:len(parts)-1] {
+			child := parent.GetChild(part)
+			if child == nil {
+				child = parent.NewPersistentInode(ctx, &ocflDir{}, fs.StableAttr{Mode: syscall.S_IFDIR})
+				parent.AddChild(part, child, false)
+			}
+			parent = child
+		}
+		parent.AddChild(parts[len(parts)-1], node, false)
+	}
+	return nil
+}
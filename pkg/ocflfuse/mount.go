@@ -0,0 +1,70 @@
+package ocflfuse
+
+import (
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+// defaultCacheTimeout is used for MountOptions fields left at zero. OCFL
+// object versions are immutable, so it's safe for the kernel to cache
+// lookups and attributes far longer than the usual few-second default.
+const defaultCacheTimeout = time.Hour
+
+// MountOptions configures the FUSE mount itself, as opposed to how the OCFL
+// tree is resolved (see Options).
+type MountOptions struct {
+	// FsName and Name are reported to the kernel as the mounted filesystem's
+	// source and type; Name defaults to "ocfl" when empty.
+	FsName string
+	Name   string
+	// Debug enables FUSE protocol debug logging.
+	Debug bool
+
+	// EntryTimeout, AttrTimeout and NegativeTimeout bound how long the
+	// kernel caches directory entries, file attributes, and negative
+	// lookups, respectively, before re-querying this filesystem. Zero uses
+	// defaultCacheTimeout (an hour), which is safe because an OCFL version,
+	// once written, never changes.
+	EntryTimeout    time.Duration
+	AttrTimeout     time.Duration
+	NegativeTimeout time.Duration
+}
+
+// Mount mounts result.Root at mountpoint using mountOpts, returning the
+// running FUSE server. Callers are responsible for calling Unmount (or Wait)
+// on the returned server.
+func Mount(mountpoint string, result *Result, mountOpts MountOptions) (*fuse.Server, error) {
+	name := mountOpts.Name
+	if name == "" {
+		name = "ocfl"
+	}
+	entryTimeout := durationOrDefault(mountOpts.EntryTimeout)
+	attrTimeout := durationOrDefault(mountOpts.AttrTimeout)
+	negativeTimeout := durationOrDefault(mountOpts.NegativeTimeout)
+
+	opts := &fs.Options{
+		EntryTimeout:    &entryTimeout,
+		AttrTimeout:     &attrTimeout,
+		NegativeTimeout: &negativeTimeout,
+		MountOptions: fuse.MountOptions{
+			FsName:  mountOpts.FsName,
+			Name:    name,
+			Debug:   mountOpts.Debug,
+			Options: []string{"ro"},
+			// Try mount(2) directly before falling back to the fusermount
+			// helper binary, which isn't always installed (e.g. minimal
+			// containers) even though /dev/fuse is available to root.
+			DirectMount: true,
+		},
+	}
+	return fs.Mount(mountpoint, result.Root, opts)
+}
+
+func durationOrDefault(d time.Duration) time.Duration {
+	if d <= 0 {
+		return defaultCacheTimeout
+	}
+	return d
+}
@@ -0,0 +1,362 @@
+// Package ocflfuse provides a read-only FUSE filesystem for OCFL objects.
+//
+// The primary entry point is [NewRoot], which resolves either a single OCFL
+// object version or an entire OCFL storage root and returns an
+// [fs.InodeEmbedder] suitable for use with [fs.Mount].
+package ocflfuse
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/hanwen/go-fuse/v2/fs"
+	ocfl "github.com/srerickson/ocfl-go"
+	"github.com/srerickson/ocfl-go/digest"
+	ocfllocal "github.com/srerickson/ocfl-go/fs/local"
+	ocfls3 "github.com/srerickson/ocfl-go/fs/s3"
+)
+
+// Info describes the resolved OCFL object and version.
+type Info struct {
+	ObjectID  string
+	Version   string
+	FileCount int
+	RootSpec  string
+	Layout    string
+	// Versions lists every version of the object, oldest first. It is only
+	// populated when Options.AllVersions was set.
+	Versions []string
+}
+
+// Result is returned by NewRoot and contains the FUSE root node
+// along with metadata about the resolved OCFL object.
+type Result struct {
+	// Root is the FUSE inode tree root, ready to pass to fs.Mount.
+	Root fs.InodeEmbedder
+	// Info describes the resolved OCFL object and version.
+	Info Info
+}
+
+// Options configures how a single object is exposed by NewRoot/NewObjectFS.
+type Options struct {
+	// AllVersions, when true, exposes every version of the object
+	// simultaneously: the chosen version's state appears at the root as
+	// before, and a `.versions` directory holds every version (v1, v2, ...)
+	// under its own subdirectory. Content shared between versions is backed
+	// by a single FUSE inode, so this costs little extra memory.
+	AllVersions bool
+
+	// CacheDir, if non-empty, enables a local read-through disk cache for
+	// S3-backed reads: content is fetched from S3 in ChunkSize-aligned
+	// chunks keyed by OCFL digest and served from disk on subsequent reads.
+	// Ignored for local storage roots.
+	CacheDir string
+	// CacheMaxBytes bounds the cache's on-disk size; least-recently-used
+	// digests are evicted once it is exceeded. Zero means unbounded.
+	CacheMaxBytes int64
+	// ChunkSize is the size of the aligned chunk fetched from S3 on a cache
+	// miss. Zero uses DefaultChunkSize.
+	ChunkSize int64
+}
+
+// NewRoot resolves storageRoot and returns a FUSE root node.
+//
+// storageRoot is an S3 URI (s3://bucket/prefix) or a local filesystem path.
+// If objectID is empty, the returned tree browses the entire storage root,
+// with one directory per object; see [NewStorageRoot]. Otherwise the tree
+// exposes a single object; see [NewObjectFS].
+func NewRoot(ctx context.Context, storageRoot, objectID, version string, opts Options) (*Result, error) {
+	if objectID == "" {
+		return NewStorageRoot(ctx, storageRoot, opts)
+	}
+	return NewObjectFS(ctx, storageRoot, objectID, version, opts)
+}
+
+// NewObjectFS resolves a single OCFL object version and returns a FUSE root
+// node exposing that version's logical file tree.
+//
+// storageRoot is an S3 URI (s3://bucket/prefix) or a local filesystem path.
+// objectID is the OCFL object identifier. version is the version to mount
+// (e.g. "v1", "v2"); pass "" for the head/latest version.
+func NewObjectFS(ctx context.Context, storageRoot, objectID, version string, opts Options) (*Result, error) {
+	backend, err := openBackend(ctx, storageRoot, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := backend.root.NewObject(ctx, objectID, ocfl.ObjectMustExist())
+	if err != nil {
+		return nil, fmt.Errorf("loading OCFL object: %w", err)
+	}
+	ver, err := resolveVersion(obj, version)
+	if err != nil {
+		return nil, err
+	}
+	files, err := buildFileMap(obj, ver)
+	if err != nil {
+		return nil, err
+	}
+
+	info := Info{
+		ObjectID:  obj.ID(),
+		Version:   ver.VNum().String(),
+		FileCount: len(files),
+		RootSpec:  string(backend.root.Spec()),
+		Layout:    layoutString(backend.root),
+	}
+
+	if !opts.AllVersions {
+		return &Result{Root: backend.newObjectRoot(files), Info: info}, nil
+	}
+
+	for _, v := range objectVersions(obj) {
+		info.Versions = append(info.Versions, v.VNum().String())
+	}
+	return &Result{
+		Root: &objectVersionsRoot{backend: backend, obj: obj, top: ver},
+		Info: info,
+	}, nil
+}
+
+// NewStorageRoot resolves storageRoot and returns a FUSE root node that
+// browses every object in it, one directory per object ID.
+//
+// Object inventories are loaded lazily: opening the storage root itself
+// only reads ocfl_layout.json and the layout extension config, so mounting
+// a repository with millions of objects is cheap. An individual object's
+// inventory is only fetched once that object's directory is entered.
+func NewStorageRoot(ctx context.Context, storageRoot string, opts Options) (*Result, error) {
+	backend, err := openBackend(ctx, storageRoot, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Root: &storageRootDir{backend: backend},
+		Info: Info{
+			RootSpec: string(backend.root.Spec()),
+			Layout:   layoutString(backend.root),
+		},
+	}, nil
+}
+
+// resolveVersion parses a version flag and returns the OCFL object version.
+func resolveVersion(obj *ocfl.Object, versionFlag string) (*ocfl.ObjectVersion, error) {
+	vnum := 0 // HEAD
+	if versionFlag != "" {
+		v := versionFlag
+		if strings.HasPrefix(v, "v") {
+			v = v[1:]
+		}
+		var n int
+		if _, err := fmt.Sscanf(v, "%d", &n); err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid version %q", versionFlag)
+		}
+		vnum = n
+	}
+	ver := obj.Version(vnum)
+	if ver == nil {
+		return nil, fmt.Errorf("version not found")
+	}
+	return ver, nil
+}
+
+// objectVersions returns every version of obj, oldest first.
+func objectVersions(obj *ocfl.Object) []*ocfl.ObjectVersion {
+	head := obj.Version(0)
+	if head == nil {
+		return nil
+	}
+	n := head.VNum().Num()
+	versions := make([]*ocfl.ObjectVersion, 0, n)
+	for i := 1; i <= n; i++ {
+		if v := obj.Version(i); v != nil {
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
+
+// contentRef identifies the content backing a logical path: its
+// object-relative path within the storage root, its OCFL digest, and,
+// when known from the inventory, its byte size.
+type contentRef struct {
+	Path   string
+	Digest string
+	// Size is the content's byte length, as recorded in the object's
+	// inventory. Zero means the inventory doesn't carry size information
+	// for this digest, and callers must stat the backend to learn it.
+	Size int64
+}
+
+// buildFileMap builds the logical path -> content mapping for an object version.
+func buildFileMap(obj *ocfl.Object, ver *ocfl.ObjectVersion) (map[string]contentRef, error) {
+	state := ver.State()
+	manifest := obj.Manifest()
+	objPath := obj.Path()
+	sizes := manifestSizes(obj)
+
+	files := make(map[string]contentRef, state.NumPaths())
+	for logicalPath, digest := range state.Paths() {
+		contentPaths := manifest[digest]
+		if len(contentPaths) == 0 {
+			return nil, fmt.Errorf("missing manifest entry for digest %s", digest)
+		}
+		files[logicalPath] = contentRef{
+			Path:   objPath + "/" + contentPaths[0],
+			Digest: digest,
+			Size:   sizes[digest],
+		}
+	}
+	return files, nil
+}
+
+// manifestSizes returns the byte size of each digest in obj's manifest, for
+// inventories that record size via the OCFL "size" fixity algorithm (see
+// digest.SIZE). Digests with no recorded size are omitted, so a missing
+// entry in the returned map means the size must be learned by stating the
+// backend.
+func manifestSizes(obj *ocfl.Object) map[string]int64 {
+	manifest := obj.Manifest()
+	if len(manifest) == 0 {
+		return nil
+	}
+	var haveSize bool
+	for _, alg := range obj.FixityAlgorithms() {
+		if alg == string(digest.SIZE) {
+			haveSize = true
+			break
+		}
+	}
+	if !haveSize {
+		return nil
+	}
+	sizes := make(map[string]int64, len(manifest))
+	for dig := range manifest {
+		sizeStr, ok := obj.GetFixity(dig)[string(digest.SIZE)]
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(sizeStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		sizes[dig] = n
+	}
+	return sizes
+}
+
+func layoutString(root *ocfl.Root) string {
+	if l := root.Layout(); l != nil {
+		return fmt.Sprintf("%v", l)
+	}
+	return ""
+}
+
+// backend bundles an open OCFL storage root with whatever is needed to turn
+// an object-relative content path into a FUSE file node, so the same
+// object/storage-root walking code works for both S3 and local storage.
+type backend struct {
+	root *ocfl.Root
+
+	s3Client  *s3.Client // nil for local backends
+	bucket    string
+	cache     BlobCache // optional read-through cache for S3 reads
+	chunkSize int64     // chunk size used to populate cache, if set
+
+	localRoot string // absolute path on disk; "" for S3 backends
+}
+
+// openBackend opens storageRoot (an s3:// URI or a local path) and returns
+// the backend used to resolve objects and file content within it.
+func openBackend(ctx context.Context, storageRoot string, opts Options) (*backend, error) {
+	if strings.HasPrefix(storageRoot, "s3://") {
+		return openS3Backend(ctx, storageRoot, opts)
+	}
+	return openLocalBackend(ctx, storageRoot)
+}
+
+func openS3Backend(ctx context.Context, storageRoot string, opts Options) (*backend, error) {
+	after := strings.TrimPrefix(storageRoot, "s3://")
+	bucket, prefix, _ := strings.Cut(after, "/")
+
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS config: %w", err)
+	}
+	s3Client := s3.NewFromConfig(cfg)
+
+	fsys := ocfls3.NewBucketFS(s3Client, bucket)
+	root, err := ocfl.NewRoot(ctx, fsys, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("opening OCFL root: %w", err)
+	}
+
+	b := &backend{root: root, s3Client: s3Client, bucket: bucket}
+	if opts.CacheDir != "" {
+		chunkSize := opts.ChunkSize
+		if chunkSize <= 0 {
+			chunkSize = DefaultChunkSize
+		}
+		cache, err := NewDiskBlobCache(DiskBlobCacheOptions{
+			Dir:       opts.CacheDir,
+			MaxBytes:  opts.CacheMaxBytes,
+			ChunkSize: chunkSize,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("creating blob cache: %w", err)
+		}
+		b.cache = cache
+		b.chunkSize = chunkSize
+	}
+	return b, nil
+}
+
+func openLocalBackend(ctx context.Context, storageRoot string) (*backend, error) {
+	absRoot, err := filepath.Abs(storageRoot)
+	if err != nil {
+		return nil, fmt.Errorf("resolving path: %w", err)
+	}
+
+	fsys, err := ocfllocal.NewFS(absRoot)
+	if err != nil {
+		return nil, fmt.Errorf("opening local FS: %w", err)
+	}
+
+	root, err := ocfl.NewRoot(ctx, fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("opening OCFL root: %w", err)
+	}
+
+	return &backend{root: root, localRoot: absRoot}, nil
+}
+
+// newFileNode returns a FUSE file inode backed by ref, content within the
+// storage root.
+func (b *backend) newFileNode(ref contentRef) fs.InodeEmbedder {
+	if b.s3Client != nil {
+		return &s3File{
+			s3Client:  b.s3Client,
+			bucket:    b.bucket,
+			s3Key:     ref.Path,
+			digest:    ref.Digest,
+			size:      ref.Size,
+			cache:     b.cache,
+			chunkSize: b.chunkSize,
+		}
+	}
+	return &localFile{path: filepath.Join(b.localRoot, filepath.FromSlash(ref.Path)), digest: ref.Digest}
+}
+
+// newObjectRoot returns the FUSE root node for a single resolved object
+// version, given its logical-path -> content file map.
+func (b *backend) newObjectRoot(files map[string]contentRef) fs.InodeEmbedder {
+	if b.s3Client != nil {
+		return &s3Root{backend: b, files: files}
+	}
+	return &localRoot{backend: b, files: files}
+}
@@ -0,0 +1,183 @@
+package ocflfuse
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testStoreRoot = "../../testdata/good-stores/reg-extension-dir-root"
+const testObjectID = "ark:123/abc"
+
+// mountForTest mounts result at a temp directory and registers cleanup.
+func mountForTest(t *testing.T, result *Result) string {
+	t.Helper()
+	mountpoint := t.TempDir()
+	server, err := Mount(mountpoint, result, MountOptions{FsName: "ocfl-test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Unmount() })
+	if err := server.WaitMount(); err != nil {
+		t.Fatal(err)
+	}
+	return mountpoint
+}
+
+func TestNewObjectFS(t *testing.T) {
+	ctx := context.Background()
+
+	result, err := NewObjectFS(ctx, testStoreRoot, testObjectID, "", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Info.ObjectID != testObjectID {
+		t.Errorf("got object ID %q, want %q", result.Info.ObjectID, testObjectID)
+	}
+	if result.Info.FileCount == 0 {
+		t.Fatal("expected files")
+	}
+	t.Logf("object %q version %s: %d files (spec %s, layout %s)",
+		result.Info.ObjectID, result.Info.Version, result.Info.FileCount,
+		result.Info.RootSpec, result.Info.Layout)
+
+	mountpoint := mountForTest(t, result)
+
+	data, err := os.ReadFile(filepath.Join(mountpoint, "a_file.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "Hello! I am a file.\n"
+	if string(data) != expected {
+		t.Errorf("got %q, want %q", string(data), expected)
+	}
+
+	entries, err := os.ReadDir(mountpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected 1 entry, got %d", len(entries))
+	}
+	if entries[0].Name() != "a_file.txt" {
+		t.Errorf("expected a_file.txt, got %s", entries[0].Name())
+	}
+}
+
+func TestNewObjectFSAllVersions(t *testing.T) {
+	ctx := context.Background()
+
+	result, err := NewObjectFS(ctx, testStoreRoot, testObjectID, "", Options{AllVersions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Info.Versions) == 0 {
+		t.Fatal("expected Info.Versions to be populated")
+	}
+
+	mountpoint := mountForTest(t, result)
+
+	data, err := os.ReadFile(filepath.Join(mountpoint, "a_file.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Hello! I am a file.\n"
+	if string(data) != expected {
+		t.Errorf("got %q, want %q", string(data), expected)
+	}
+
+	for _, v := range result.Info.Versions {
+		if _, err := os.Stat(filepath.Join(mountpoint, ".versions", v)); err != nil {
+			t.Errorf("expected .versions/%s to exist: %v", v, err)
+		}
+	}
+}
+
+func TestNewObjectFSWithVersion(t *testing.T) {
+	ctx := context.Background()
+
+	result, err := NewObjectFS(ctx, testStoreRoot, testObjectID, "v1", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mountpoint := mountForTest(t, result)
+
+	data, err := os.ReadFile(filepath.Join(mountpoint, "a_file.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "Hello! I am a file.\n"
+	if string(data) != expected {
+		t.Errorf("got %q, want %q", string(data), expected)
+	}
+}
+
+func TestNewStorageRoot(t *testing.T) {
+	ctx := context.Background()
+
+	result, err := NewStorageRoot(ctx, testStoreRoot, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mountpoint := mountForTest(t, result)
+
+	entries, err := os.ReadDir(mountpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, e := range entries {
+		name, err := decodeObjectDirName(e.Name())
+		if err != nil {
+			t.Errorf("undecodable object dir name %q: %v", e.Name(), err)
+			continue
+		}
+		if name == testObjectID {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected object %q among %v", testObjectID, entries)
+	}
+
+	data, err := os.ReadFile(filepath.Join(mountpoint, encodeObjectDirName(testObjectID), "a_file.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Hello! I am a file.\n"
+	if string(data) != expected {
+		t.Errorf("got %q, want %q", string(data), expected)
+	}
+
+	if _, err := os.Stat(filepath.Join(mountpoint, encodeObjectDirName(testObjectID), ".versions", "v1")); err != nil {
+		t.Errorf("expected .versions/v1 to exist: %v", err)
+	}
+}
+
+func TestResolveVersionErrors(t *testing.T) {
+	ctx := context.Background()
+
+	// Invalid version
+	if _, err := NewObjectFS(ctx, testStoreRoot, testObjectID, "abc", Options{}); err == nil {
+		t.Error("expected error for invalid version")
+	}
+
+	// Non-existent version
+	if _, err := NewObjectFS(ctx, testStoreRoot, testObjectID, "v99", Options{}); err == nil {
+		t.Error("expected error for non-existent version")
+	}
+
+	// Valid
+	if _, err := NewObjectFS(ctx, testStoreRoot, testObjectID, "v1", Options{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := NewObjectFS(ctx, testStoreRoot, testObjectID, "", Options{}); err != nil {
+		t.Errorf("unexpected error for HEAD: %v", err)
+	}
+}
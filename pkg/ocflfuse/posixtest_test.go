@@ -0,0 +1,202 @@
+package ocflfuse
+
+// A small posixtest-style conformance suite covering the read-only
+// operations an OCFL mount must get right: open/read, readdir, stat,
+// negative lookup. go-fuse's own posixtest package (posixtest.FileBasic,
+// posixtest.ReadDir, etc.) all start by writing to the mount, so none of it
+// applies as-is to this read-only filesystem; these cases are hand-written
+// against the same operations instead of reusing that package.
+//
+// Chunk-boundary correctness of the S3 read path (s3File.readCached) is
+// exercised separately in TestS3FileReadCachedChunkBoundaries, against a
+// minimal in-process fake of S3's ranged GetObject. True S3 coverage (a real
+// fake-S3 server behind the aws-sdk-go-v2 client, or a real bucket) isn't
+// covered here: this backend has no injectable endpoint override, and
+// hand-rolling one just for tests is more production surface than this fix
+// warrants.
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func TestPosixConformance(t *testing.T) {
+	ctx := context.Background()
+	result, err := NewObjectFS(ctx, testStoreRoot, testObjectID, "", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mountpoint := mountForTest(t, result)
+
+	t.Run("OpenRead", func(t *testing.T) { posixOpenRead(t, mountpoint) })
+	t.Run("ReadDir", func(t *testing.T) { posixReadDir(t, mountpoint) })
+	t.Run("Stat", func(t *testing.T) { posixStat(t, mountpoint) })
+	t.Run("NegativeLookup", func(t *testing.T) { posixNegativeLookup(t, mountpoint) })
+	t.Run("Symlink", func(t *testing.T) { posixSymlinkUnsupported(t, mountpoint) })
+}
+
+func posixOpenRead(t *testing.T, mountpoint string) {
+	f, err := os.Open(filepath.Join(mountpoint, "a_file.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Hello! I am a file.\n"; string(data) != want {
+		t.Errorf("got %q, want %q", string(data), want)
+	}
+}
+
+func posixReadDir(t *testing.T, mountpoint string) {
+	entries, err := os.ReadDir(mountpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 {
+		t.Fatal("expected at least one entry")
+	}
+	for _, e := range entries {
+		if e.Name() == "." || e.Name() == ".." {
+			t.Errorf("readdir returned %q, should be filtered by the kernel", e.Name())
+		}
+	}
+}
+
+func posixStat(t *testing.T, mountpoint string) {
+	info, err := os.Stat(filepath.Join(mountpoint, "a_file.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.IsDir() {
+		t.Error("a_file.txt should not be a directory")
+	}
+	if info.Mode().Perm()&0222 != 0 {
+		t.Errorf("expected a read-only mode, got %v", info.Mode())
+	}
+	if info.Size() == 0 {
+		t.Error("expected non-zero size")
+	}
+}
+
+func posixNegativeLookup(t *testing.T, mountpoint string) {
+	_, err := os.Stat(filepath.Join(mountpoint, "does-not-exist.txt"))
+	if !os.IsNotExist(err) {
+		t.Errorf("got %v, want a not-exist error", err)
+	}
+}
+
+// posixSymlinkUnsupported documents that this read-only FUSE tree never
+// creates symlinks; the real posixtest.Symlink case doesn't apply.
+func posixSymlinkUnsupported(t *testing.T, mountpoint string) {
+	entries, err := os.ReadDir(mountpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Type()&os.ModeSymlink != 0 {
+			t.Errorf("unexpected symlink entry %q in a read-only OCFL mount", e.Name())
+		}
+	}
+}
+
+// rangeGetObjectServer is a minimal fake of S3's ranged GetObject: it serves
+// byte ranges of a single fixed blob from an httptest.Server, ignoring
+// bucket/key and auth entirely. It's just enough to drive s3File.readCached
+// through a real aws-sdk-go-v2 S3 client and a real HTTP round trip.
+func rangeGetObjectServer(t *testing.T, blob []byte) *s3.Client {
+	t.Helper()
+	rangeRE := regexp.MustCompile(`^bytes=(\d+)-(\d+)$`)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m := rangeRE.FindStringSubmatch(r.Header.Get("Range"))
+		if m == nil {
+			http.Error(w, "expected a byte Range header", http.StatusBadRequest)
+			return
+		}
+		start, _ := strconv.ParseInt(m[1], 10, 64)
+		end, _ := strconv.ParseInt(m[2], 10, 64)
+		if end >= int64(len(blob)) {
+			end = int64(len(blob)) - 1
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(blob)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(blob[start : end+1])
+	}))
+	t.Cleanup(srv.Close)
+
+	client := s3.New(s3.Options{
+		Region:       "us-east-1",
+		Credentials:  credentials.NewStaticCredentialsProvider("test", "test", ""),
+		BaseEndpoint: aws.String(srv.URL),
+		UsePathStyle: true,
+	})
+	return client
+}
+
+// TestS3FileReadCachedChunkBoundaries exercises s3File.readCached against a
+// cache populated in small chunks fetched over a real (fake) ranged
+// GetObject, guarding against the off-by-one errors that chunk-boundary
+// arithmetic tends to produce.
+func TestS3FileReadCachedChunkBoundaries(t *testing.T) {
+	const chunkSize = 16
+	want := make([]byte, chunkSize*5+7)
+	if _, err := rand.Read(want); err != nil {
+		t.Fatal(err)
+	}
+
+	cache, err := NewDiskBlobCache(DiskBlobCacheOptions{Dir: t.TempDir(), ChunkSize: chunkSize})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := &s3File{
+		s3Client:  rangeGetObjectServer(t, want),
+		bucket:    "test-bucket",
+		s3Key:     "test-key",
+		digest:    "large-file",
+		size:      int64(len(want)),
+		cache:     cache,
+		chunkSize: chunkSize,
+	}
+
+	offsets := []int64{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, int64(len(want)) - 4}
+	for _, off := range offsets {
+		buf := make([]byte, 4)
+		res, errno := f.Read(context.Background(), nil, buf, off)
+		if errno != 0 {
+			t.Fatalf("Read(off=%d): errno %v", off, errno)
+		}
+		got, _ := res.Bytes(nil)
+		end := off + int64(len(got))
+		if string(got) != string(want[off:end]) {
+			t.Errorf("Read(off=%d) mismatch: got %x, want %x", off, got, want[off:end])
+		}
+	}
+
+	// Re-reading the same offsets should now be served entirely from cache.
+	for _, off := range offsets {
+		buf := make([]byte, 4)
+		if n, ok := cache.Get("large-file", off, buf); !ok {
+			t.Errorf("Get(off=%d): expected cache hit on second pass", off)
+		} else if end := off + int64(n); string(buf[:n]) != string(want[off:end]) {
+			t.Errorf("cached Get(off=%d) mismatch", off)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+// Package ocfls3gw serves OCFL storage roots and objects over a minimal
+// S3-compatible HTTP API, giving read-only access to callers that can't use
+// a FUSE mount (Windows, containers without /dev/fuse, Lambda). Each OCFL
+// object ID is exposed as a bucket; an object's version is selected with the
+// standard S3 ?versionId= query parameter.
+package ocfls3gw
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	ocflfuse "github.com/srerickson/ocfl-fuse/pkg/ocflfuse"
+)
+
+// Gateway is an http.Handler serving a single OCFL storage root.
+type Gateway struct {
+	backend *ocflfuse.Backend
+}
+
+// Open opens storageRoot for serving.
+func Open(ctx context.Context, storageRoot string, opts ocflfuse.Options) (*Gateway, error) {
+	backend, err := ocflfuse.OpenBackend(ctx, storageRoot, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Gateway{backend: backend}, nil
+}
+
+func (gw *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Split on the escaped path, not r.URL.Path: a bucket name is an
+	// EncodeObjectID result, which percent-encodes "/" as "%2F" so an
+	// object ID containing a slash still occupies a single path segment.
+	// r.URL.Path has already been %-decoded by net/http, which would
+	// collapse that %2F back into a literal "/" and misalign the split.
+	escapedBucket, escapedKey, _ := strings.Cut(strings.TrimPrefix(r.URL.EscapedPath(), "/"), "/")
+	bucket := escapedBucket
+	key, err := url.PathUnescape(escapedKey)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidArgument", err)
+		return
+	}
+
+	switch {
+	case bucket == "":
+		gw.listBuckets(w, r)
+	case key == "" && hasQueryParam(r, "versioning"):
+		gw.getBucketVersioning(w, r, bucket)
+	case key == "":
+		gw.listObjectsV2(w, r, bucket)
+	case r.Method == http.MethodHead:
+		gw.headObject(w, r, bucket, key)
+	default:
+		gw.getObject(w, r, bucket, key)
+	}
+}
+
+func hasQueryParam(r *http.Request, name string) bool {
+	_, ok := r.URL.Query()[name]
+	return ok
+}
+
+// --- XML response bodies, matching the subset of the S3 API this gateway implements ---
+
+type listAllMyBucketsResult struct {
+	XMLName xml.Name   `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListAllMyBucketsResult"`
+	Buckets []s3Bucket `xml:"Buckets>Bucket"`
+}
+
+type s3Bucket struct {
+	Name string `xml:"Name"`
+}
+
+type listBucketResult struct {
+	XMLName        xml.Name      `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListBucketResult"`
+	Name           string        `xml:"Name"`
+	Prefix         string        `xml:"Prefix"`
+	Delimiter      string        `xml:"Delimiter,omitempty"`
+	KeyCount       int           `xml:"KeyCount"`
+	IsTruncated    bool          `xml:"IsTruncated"`
+	Contents       []s3Object    `xml:"Contents"`
+	CommonPrefixes []s3CommonPfx `xml:"CommonPrefixes,omitempty"`
+}
+
+type s3Object struct {
+	Key  string `xml:"Key"`
+	Size int64  `xml:"Size"`
+	ETag string `xml:"ETag"`
+}
+
+type s3CommonPfx struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type versioningConfiguration struct {
+	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ VersioningConfiguration"`
+	Status  string   `xml:"Status"`
+}
+
+type errorResponse struct {
+	XMLName xml.Name `xml:"Error"`
+	Code    string   `xml:"Code"`
+	Message string   `xml:"Message"`
+}
+
+func writeXML(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	w.Write([]byte(xml.Header))
+	_ = xml.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code string, err error) {
+	writeXML(w, status, errorResponse{Code: code, Message: err.Error()})
+}
+
+// --- ListBuckets: one bucket per OCFL object ID in the storage root ---
+
+func (gw *Gateway) listBuckets(w http.ResponseWriter, r *http.Request) {
+	var buckets []s3Bucket
+	for objectID, err := range gw.backend.Objects(r.Context()) {
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "InternalError", err)
+			return
+		}
+		buckets = append(buckets, s3Bucket{Name: ocflfuse.EncodeObjectID(objectID)})
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Name < buckets[j].Name })
+	writeXML(w, http.StatusOK, listAllMyBucketsResult{Buckets: buckets})
+}
+
+// --- GetBucketVersioning: static "Enabled", since every OCFL object is versioned ---
+
+func (gw *Gateway) getBucketVersioning(w http.ResponseWriter, r *http.Request, bucket string) {
+	if _, err := gw.resolveObject(r, bucket); err != nil {
+		writeError(w, http.StatusNotFound, "NoSuchBucket", err)
+		return
+	}
+	writeXML(w, http.StatusOK, versioningConfiguration{Status: "Enabled"})
+}
+
+// --- ListObjectsV2: walks the resolved version's logical file map ---
+
+func (gw *Gateway) listObjectsV2(w http.ResponseWriter, r *http.Request, bucket string) {
+	obj, err := gw.resolveObject(r, bucket)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "NoSuchBucket", err)
+		return
+	}
+
+	prefix := r.URL.Query().Get("prefix")
+	delimiter := r.URL.Query().Get("delimiter")
+
+	var contents []s3Object
+	prefixSet := map[string]bool{}
+	for path, info := range obj.Files() {
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		rest := path[len(prefix):]
+		if delimiter != "" {
+			if i := strings.Index(rest, delimiter); i >= 0 {
+				prefixSet[prefix+rest[:i+len(delimiter)]] = true
+				continue
+			}
+		}
+		contents = append(contents, s3Object{Key: path, Size: info.Size, ETag: info.Digest})
+	}
+	sort.Slice(contents, func(i, j int) bool { return contents[i].Key < contents[j].Key })
+
+	var commonPrefixes []s3CommonPfx
+	for p := range prefixSet {
+		commonPrefixes = append(commonPrefixes, s3CommonPfx{Prefix: p})
+	}
+	sort.Slice(commonPrefixes, func(i, j int) bool { return commonPrefixes[i].Prefix < commonPrefixes[j].Prefix })
+
+	writeXML(w, http.StatusOK, listBucketResult{
+		Name:           bucket,
+		Prefix:         prefix,
+		Delimiter:      delimiter,
+		KeyCount:       len(contents) + len(commonPrefixes),
+		Contents:       contents,
+		CommonPrefixes: commonPrefixes,
+	})
+}
+
+// --- HeadObject / GetObject: map onto ResolvedObject.Open, including ranges ---
+
+func (gw *Gateway) headObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	obj, err := gw.resolveObject(r, bucket)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "NoSuchBucket", err)
+		return
+	}
+	info, ok := obj.Files()[key]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchKey", fmt.Errorf("%s: not found", key))
+		return
+	}
+	w.Header().Set("ETag", info.Digest)
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	w.WriteHeader(http.StatusOK)
+}
+
+func (gw *Gateway) getObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	obj, err := gw.resolveObject(r, bucket)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "NoSuchBucket", err)
+		return
+	}
+
+	start, end := int64(0), int64(-1)
+	status := http.StatusOK
+	if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+		start, end, err = parseRange(rangeHeader)
+		if err != nil {
+			writeError(w, http.StatusRequestedRangeNotSatisfiable, "InvalidRange", err)
+			return
+		}
+		status = http.StatusPartialContent
+	}
+
+	body, size, err := obj.Open(r.Context(), key, start, end)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "NoSuchKey", err)
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("ETag", obj.Files()[key].Digest)
+	if status == http.StatusPartialContent {
+		realEnd := end
+		if realEnd < 0 {
+			realEnd = size - 1
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, realEnd, size))
+	}
+	w.WriteHeader(status)
+	_, _ = io.Copy(w, body)
+}
+
+// resolveObject decodes bucket as an object ID and resolves its version,
+// honoring ?versionId=vN the way S3 selects an older object version.
+func (gw *Gateway) resolveObject(r *http.Request, bucket string) (*ocflfuse.ResolvedObject, error) {
+	objectID, err := ocflfuse.DecodeObjectID(bucket)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bucket name %q: %w", bucket, err)
+	}
+	version := r.URL.Query().Get("versionId")
+	return gw.backend.ResolveObject(r.Context(), objectID, version)
+}
+
+func parseRange(header string) (start, end int64, err error) {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok {
+		return 0, -1, errors.New("unsupported Range unit")
+	}
+	before, after, _ := strings.Cut(spec, "-")
+	start, err = strconv.ParseInt(before, 10, 64)
+	if err != nil {
+		return 0, -1, fmt.Errorf("invalid range %q: %w", header, err)
+	}
+	if after == "" {
+		return start, -1, nil
+	}
+	end, err = strconv.ParseInt(after, 10, 64)
+	if err != nil {
+		return 0, -1, fmt.Errorf("invalid range %q: %w", header, err)
+	}
+	return start, end, nil
+}
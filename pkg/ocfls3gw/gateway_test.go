@@ -0,0 +1,71 @@
+package ocfls3gw
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ocflfuse "github.com/srerickson/ocfl-fuse/pkg/ocflfuse"
+)
+
+const (
+	testStoreRoot = "../../testdata/good-stores/reg-extension-dir-root"
+	testObjectID  = "ark:123/abc"
+)
+
+func TestGatewayGetObject(t *testing.T) {
+	gw, err := Open(context.Background(), testStoreRoot, ocflfuse.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(gw)
+	defer srv.Close()
+
+	bucket := ocflfuse.EncodeObjectID(testObjectID)
+	resp, err := http.Get(srv.URL + "/" + bucket + "/a_file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Hello! I am a file.\n"; string(data) != want {
+		t.Errorf("got %q, want %q", string(data), want)
+	}
+}
+
+func TestGatewayListBuckets(t *testing.T) {
+	gw, err := Open(context.Background(), testStoreRoot, ocflfuse.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(gw)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bucket := ocflfuse.EncodeObjectID(testObjectID)
+	if !strings.Contains(string(data), bucket) {
+		t.Errorf("expected bucket %q in response: %s", bucket, data)
+	}
+}
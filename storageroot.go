@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"path"
+	"strings"
+	"syscall"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+// errNotObjectRoot signals that an S3 prefix has no inventory.json, and so
+// is an intermediate layout directory rather than an OCFL object root.
+var errNotObjectRoot = errors.New("not an OCFL object root")
+
+// probeInventory fetches and parses the inventory.json at prefix, if any.
+// It returns errNotObjectRoot (wrapped) when prefix has no inventory.json,
+// so callers can tell "not an object" apart from a real fetch/parse error.
+func (s *S3Backend) probeInventory(ctx context.Context, prefix string) (*OCFLInventory, error) {
+	data, err := s.GetObject(ctx, path.Join(prefix, "inventory.json"))
+	if err != nil {
+		var nsk *types.NoSuchKey
+		if errors.As(err, &nsk) {
+			return nil, errNotObjectRoot
+		}
+		return nil, err
+	}
+	var inv OCFLInventory
+	if err := json.Unmarshal(data, &inv); err != nil {
+		return nil, fmt.Errorf("parsing inventory at %s: %w", prefix, err)
+	}
+	return &inv, nil
+}
+
+// listChildPrefixes lists the immediate subdirectories of prefix (an object
+// path relative to the backend's configured prefix, "" for the storage
+// root), one ListObjectsV2 call per page.
+func (s *S3Backend) listChildPrefixes(ctx context.Context, prefix string) ([]string, error) {
+	fullPrefix := s.fullKey(prefix)
+	if fullPrefix != "" && !strings.HasSuffix(fullPrefix, "/") {
+		fullPrefix += "/"
+	}
+
+	var children []string
+	var token *string
+	delimiter := "/"
+	for {
+		resp, err := s.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+			Bucket:            &s.bucket,
+			Prefix:            &fullPrefix,
+			Delimiter:         &delimiter,
+			ContinuationToken: token,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("listing %s: %w", fullPrefix, err)
+		}
+		for _, cp := range resp.CommonPrefixes {
+			name := strings.TrimSuffix(strings.TrimPrefix(*cp.Prefix, fullPrefix), "/")
+			if prefix == "" {
+				children = append(children, name)
+			} else {
+				children = append(children, prefix+"/"+name)
+			}
+		}
+		if resp.IsTruncated == nil || !*resp.IsTruncated {
+			break
+		}
+		token = resp.NextContinuationToken
+	}
+	return children, nil
+}
+
+// walkObjects recursively visits every OCFL object root under the storage
+// root, discovering them by listing one directory level at a time and
+// probing each entry for an inventory.json. This is the fallback a client
+// with no layout metadata must use; it's the same cost storageRootDir.Lookup
+// avoids by reversing S3Backend.ObjectPath for a known object ID.
+func (s *S3Backend) walkObjects(ctx context.Context, visit func(objectPath string, inv *OCFLInventory)) error {
+	return s.walkObjectsUnder(ctx, "", visit)
+}
+
+func (s *S3Backend) walkObjectsUnder(ctx context.Context, prefix string, visit func(string, *OCFLInventory)) error {
+	children, err := s.listChildPrefixes(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		inv, err := s.probeInventory(ctx, child)
+		switch {
+		case err == nil:
+			visit(child, inv)
+		case errors.Is(err, errNotObjectRoot):
+			if err := s.walkObjectsUnder(ctx, child, visit); err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeObjectID turns an OCFL object ID into a filesystem-safe, reversible
+// directory name.
+func encodeObjectID(objectID string) string {
+	return url.PathEscape(objectID)
+}
+
+// decodeObjectID reverses encodeObjectID.
+func decodeObjectID(name string) (string, error) {
+	return url.PathUnescape(name)
+}
+
+// storageRootDir is the FUSE root node for browsing an entire OCFL storage
+// root rather than a single mounted object. Lookup resolves a requested
+// object ID straight to its path via S3Backend.ObjectPath, without listing;
+// Readdir, which must enumerate every object, walks the storage root's
+// layout and probes each leaf for an inventory.json. Neither happens until
+// the directory is actually opened, so mounting a repository with millions
+// of objects is O(1).
+type storageRootDir struct {
+	fs.Inode
+	backend *S3Backend
+}
+
+var _ = (fs.NodeLookuper)((*storageRootDir)(nil))
+var _ = (fs.NodeReaddirer)((*storageRootDir)(nil))
+
+func (d *storageRootDir) Lookup(ctx context.Context, name string, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+	objectID, err := decodeObjectID(name)
+	if err != nil {
+		return nil, syscall.ENOENT
+	}
+	objectPath := d.backend.ObjectPath(objectID)
+	inv, err := d.backend.probeInventory(ctx, objectPath)
+	if err != nil {
+		return nil, syscall.ENOENT
+	}
+	child := &objectDir{backend: d.backend, objectPath: objectPath, inventory: inv}
+	return d.NewInode(ctx, child, fs.StableAttr{Mode: syscall.S_IFDIR}), 0
+}
+
+func (d *storageRootDir) Readdir(ctx context.Context) (fs.DirStream, syscall.Errno) {
+	var entries []fuse.DirEntry
+	err := d.backend.walkObjects(ctx, func(objectPath string, inv *OCFLInventory) {
+		entries = append(entries, fuse.DirEntry{
+			Name: encodeObjectID(inv.ID),
+			Mode: syscall.S_IFDIR,
+		})
+	})
+	if err != nil {
+		log.Printf("listing objects: %v", err)
+	}
+	return fs.NewListDirStream(entries), 0
+}
+
+// objectDir is the FUSE directory for a single object found while browsing
+// a storage root. It exposes a `HEAD` directory plus one directory per
+// version in the inventory, and a `metadata.json` file summarizing the
+// inventory. Its inventory is already in memory by the time it's entered
+// (storageRootDir.Lookup fetched it to confirm this was an object), so
+// populating it here costs no further S3 requests.
+type objectDir struct {
+	fs.Inode
+	backend    *S3Backend
+	objectPath string
+	inventory  *OCFLInventory
+}
+
+var _ = (fs.NodeOnAdder)((*objectDir)(nil))
+
+func (d *objectDir) OnAdd(ctx context.Context) {
+	inv := d.inventory
+	for v := range inv.Versions {
+		files, err := buildFileMap(inv, d.objectPath, v)
+		if err != nil {
+			log.Printf("building file map for %q %s: %v", inv.ID, v, err)
+			continue
+		}
+		s3Files := toS3Files(d.backend, files)
+
+		verDir := d.NewPersistentInode(ctx, &OCFLDir{}, fs.StableAttr{Mode: syscall.S_IFDIR})
+		populateFileTree(ctx, verDir, d.backend, s3Files)
+		d.AddChild(v, verDir, false)
+
+		if v == inv.Head {
+			headDir := d.NewPersistentInode(ctx, &OCFLDir{}, fs.StableAttr{Mode: syscall.S_IFDIR})
+			populateFileTree(ctx, headDir, d.backend, s3Files)
+			d.AddChild("HEAD", headDir, false)
+		}
+	}
+
+	meta := d.NewPersistentInode(ctx, newMetadataFile(inv), fs.StableAttr{Mode: syscall.S_IFREG})
+	d.AddChild("metadata.json", meta, false)
+}
+
+// objectMetadata is the JSON shape exposed by an object's metadata.json.
+type objectMetadata struct {
+	ID       string                           `json:"id"`
+	Spec     string                           `json:"spec"`
+	Head     string                           `json:"head"`
+	Versions map[string]objectMetadataVersion `json:"versions"`
+}
+
+type objectMetadataVersion struct {
+	Created string    `json:"created"`
+	Message string    `json:"message,omitempty"`
+	User    *OCFLUser `json:"user,omitempty"`
+}
+
+func newObjectMetadata(inv *OCFLInventory) objectMetadata {
+	versions := make(map[string]objectMetadataVersion, len(inv.Versions))
+	for v, data := range inv.Versions {
+		versions[v] = objectMetadataVersion{Created: data.Created, Message: data.Message, User: data.User}
+	}
+	return objectMetadata{ID: inv.ID, Spec: inv.Type, Head: inv.Head, Versions: versions}
+}
+
+// metadataFile is a synthetic, read-only file exposing an object's parsed
+// inventory as formatted JSON.
+type metadataFile struct {
+	fs.Inode
+	data []byte
+}
+
+var _ = (fs.NodeGetattrer)((*metadataFile)(nil))
+var _ = (fs.NodeOpener)((*metadataFile)(nil))
+var _ = (fs.NodeReader)((*metadataFile)(nil))
+
+func newMetadataFile(inv *OCFLInventory) *metadataFile {
+	data, err := json.MarshalIndent(newObjectMetadata(inv), "", "  ")
+	if err != nil {
+		data = []byte(fmt.Sprintf("{%q: %q}", "error", err.Error()))
+	}
+	return &metadataFile{data: data}
+}
+
+func (f *metadataFile) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
+	out.Mode = 0444 | syscall.S_IFREG
+	out.Size = uint64(len(f.data))
+	return 0
+}
+
+func (f *metadataFile) Open(ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
+	return nil, fuse.FOPEN_KEEP_CACHE, 0
+}
+
+func (f *metadataFile) Read(ctx context.Context, fh fs.FileHandle, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
+	if off < 0 || off > int64(len(f.data)) {
+		return fuse.ReadResultData(nil), 0
+	}
+	end := off + int64(len(dest))
+	if end > int64(len(f.data)) {
+		end = int64(len(f.data))
+	}
+	return fuse.ReadResultData(f.data[off:end]), 0
+}